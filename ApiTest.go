@@ -2,24 +2,98 @@ package gotest
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httputil"
 	"net/url"
 	"os"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // ApiTestResult is the result of a test case.
 type ApiTestResult struct {
+	TestNumber      int64         // TestNumber is the number of the test case, its key in the Result of the ApiTest.
 	TestStatus      bool          // TestStatus is the status of the test case.
+	TestName        string        // TestName is the name of the test case, if available.
 	TestDescription string        // TestDescription is the description of the test case.
-	TestError       interface{}   // TestError is the error of the test case, if available.
+	TestError       *ApiTestError // TestError is the error of the test case, if available.
 	TestTime        time.Duration // TestTime is the time of the test case.
+	TestRetries     int           // TestRetries is the count of retried requests of the test case.
+	TestStartTime   time.Time     // TestStartTime is the time the test case started sending its request.
+	TestEndTime     time.Time     // TestEndTime is the time the test case received its response.
+	TestSection     string        // TestSection is the name of the section of the test case, if available.
+	TestIndex       int           // TestIndex is the 1-based position of the test case in the requests of Run, if any.
+	TestDuplicateOf int64         // TestDuplicateOf is the number of an earlier test case of the same name or details.
+	TestSoft        bool          // TestSoft is whether the test case is Soft, so its failure does not fail the run.
+
+	TestPolls           int           // TestPolls is the count of requests sent by CreateEventuallyTest.
+	TestConsistentAfter time.Duration // TestConsistentAfter is the time CreateEventuallyTest took to pass the checks.
+
+	TestSteps []ApiTestResult // TestSteps is the results of the steps run by RunFlow, up to the first failed one.
+
+	TestNotRun bool // TestNotRun is whether the test case was not run by Run, because MaxSuiteDuration was exceeded.
+
+	TestTags    []string // TestTags is the tags of the test case.
+	TestIgnored bool     // TestIgnored is whether a tag of the test case is in ExitIgnoreTags, which makes it Soft.
+
+	TestBodiesDropped bool // TestBodiesDropped is whether the captured bodies were dropped for MaxRetainedBodyBytes.
+
+	// TestDuplicateRequestOf is the number of an earlier test case with an identical request, see
+	// DetectDuplicateRequests.
+	TestDuplicateRequestOf int64
+
+	TestRequest  *ApiTestRequestCapture  // TestRequest is the captured request of the test case, if available.
+	TestResponse *ApiTestResponseCapture // TestResponse is the captured response of the test case, if available.
+
+	request *ApiTestRequest // request is the request of the test case, retained to rerun it.
+	baseUrl string          // baseUrl is the base URL the test case was run against.
+}
+
+// setTime function sets the start time, the end time and the time of a test case started at startTime.
+func (r *ApiTestResult) setTime(startTime time.Time) {
+	r.TestStartTime = startTime
+	r.TestEndTime = time.Now()
+	r.TestTime = r.TestEndTime.Sub(startTime)
+}
+
+// ApiTestRequestCapture is the request sent for a test case.
+type ApiTestRequestCapture struct {
+	Method           string      // Method is the method of the request.
+	Url              string      // Url is the complete URL of the request.
+	Header           http.Header // Header is the headers of the request.
+	Body             []byte      // Body is the body of the request.
+	TransferEncoding []string    // TransferEncoding is the transfer encodings of the body, empty for a Content-Length.
+}
+
+// ApiTestResponseCapture is the response received for a test case.
+type ApiTestResponseCapture struct {
+	Status  int         // Status is the status code of the response.
+	Proto   string      // Proto is the protocol of the response, like "HTTP/1.1" or "HTTP/2.0".
+	Header  http.Header // Header is the headers of the response.
+	Body    []byte      // Body is the body of the response.
+	Trailer http.Header // Trailer is the trailers of the response, read after the body.
+	Url     string      // Url is the URL of the response, the one of the last request of the followed redirects.
+
+	// ChunkTimes is the times the chunks of the body were read at, since the request was sent, recorded only when the
+	// test case has a MaxFirstByteTime or MaxChunkInterval.
+	ChunkTimes []time.Duration
+
+	Tls *ApiTestTlsCapture // Tls is the TLS details of the connection of the response, for HTTPS requests.
+
+	// Redirects is the URLs of the followed redirects in order, recorded only when the test case has an
+	// ExpectedRedirectChain or ExpectedRedirectChainPatterns.
+	Redirects []string
 }
 
 // ApiTest is a struct that contains the test cases for an API.
@@ -27,21 +101,371 @@ type ApiTest struct {
 	Tests       int64                   // Tests is the count fo total test cases.
 	PassedTests int64                   // PassedTests is the count of passed test cases.
 	FailedTests int64                   // FailedTests is the count of failed test cases.
+	SoftFailed  int64                   // SoftFailed is the count of failed Soft test cases, included in FailedTests.
+	NotRunTests int64                   // NotRunTests is the count of test cases not run within MaxSuiteDuration.
 	Result      map[int64]ApiTestResult // Result is the result of the test cases.
 	Server      *httptest.Server        // Server is the server for the test cases.
 	ServerMux   *http.ServeMux          // ServerMux is the mux for the server.
+	Client      *http.Client            // Client is the client sending the requests, defaults to http.DefaultClient.
+	BaseUrl     string                  // BaseUrl is the base URL of the API calls, defaults to the URL of Server.
+
+	// TokenProvider provides the bearer token of the test cases without an explicit BearerToken. The token is cached
+	// and fetched again when the server responds with 401 Unauthorized, after which the request is sent once more.
+	TokenProvider func() (string, error)
+
+	// OAuth2 is the client credentials the bearer token of the test cases without an explicit BearerToken is fetched
+	// with, instead of TokenProvider. The token is fetched again when it expires, or on 401 Unauthorized responses.
+	OAuth2 *ApiTestOAuth2
+
+	Verbose       bool      // Verbose adds a line-by-line diff of the expected and actual body to body mismatch errors.
+	DumpRequests  bool      // DumpRequests prints every outgoing request to the Output before it is sent.
+	DumpResponses bool      // DumpResponses prints every received response to the Output.
+	Output        io.Writer // Output is the writer of the report and the default Logger, defaults to os.Stdout.
+	RedactHeaders []string  // RedactHeaders is the list of headers redacted in addition to DefaultRedactHeaders.
+
+	// Logger is the logger of the diagnostic output, like the dumps, which defaults to writing every message as is to
+	// the Output. Use a *slog.Logger to route it through structured logging.
+	Logger ApiTestLogger
+
+	// RateLimit is the minimum interval between two requests sent by the ApiTest, including retried requests. The
+	// limit is shared by every test case, so test cases running in parallel are throttled together.
+	RateLimit time.Duration
+
+	Retries       int           // Retries is the maximum count of retries of a request on a transport error.
+	RetryDelay    time.Duration // RetryDelay is the delay before retrying a request.
+	RetryOnStatus []int         // RetryOnStatus is the status codes also retried, honoring Retry-After on 429 and 503.
+
+	// DisableKeepAlives sends every request on a fresh connection that is closed after the response, like the
+	// DisableKeepAlives of http.Transport but whatever the Client. It makes every request pay for the connection
+	// setup, so the times of the test cases are longer.
+	DisableKeepAlives bool
+
+	// Validator validates the values decoded for ExpectedShape, like the Struct method of a go-playground/validator
+	// instance. Without it, only the decoding of the body is checked.
+	Validator func(value interface{}) error
+
+	// XsdValidator validates the XML body of a response against the XML schema file of its ExpectedXsd, like with a
+	// libxml2 binding, and returns the validation errors with their lines.
+	XsdValidator func(schemaPath string, body []byte) error
+
+	// JsonSchemaValidator validates the JSON body of a response against a JSON schema file of its ExpectedSchemaOneOf,
+	// and returns the validation errors. See the gotestjsonschema package.
+	JsonSchemaValidator func(schemaPath string, body []byte) error
+
+	// BodyComparers is the comparers of ExpectedBody and ExpectedBodyOneOf for the responses of a media type, like
+	// application/x-protobuf, instead of comparing them as JSON or text. See the gotestproto package for protobuf.
+	BodyComparers map[string]ApiTestBodyComparer
+
+	// JsonPathEvaluator evaluates the full JSONPath expressions of ExpectedJsonPath against a JSON body. See the
+	// gotestjsonpath package, which keeps the JSONPath dependency out of this package.
+	JsonPathEvaluator func(path string, body []byte) (interface{}, error)
+
+	Variables map[string]interface{} // Variables is the variables used as "{{name}}" in the requests of test cases.
+
+	Requests []ApiTestRequest // Requests is the test cases of the ApiTest run by RunSuites.
+
+	// PerfBaseline is the JSON file of the baseline times of the test cases, by name or else description. A test case
+	// slower than its baseline time by more than PerfTolerancePct percent fails.
+	PerfBaseline       string
+	PerfTolerancePct   float64 // PerfTolerancePct is the percentage a test case may be slower than its baseline time.
+	UpdatePerfBaseline bool    // UpdatePerfBaseline writes the times to PerfBaseline instead of checking them.
+
+	LoopChangesOnly bool // LoopChangesOnly logs the cycles of RunLoop only when a test case changed status.
+
+	// FailOnServerError fails the test cases receiving a 5xx response, whatever else they check, unless their
+	// ExpectedStatus is a 5xx status code or their StatusMatcher or ExpectedStatusRange accepts it. It catches the
+	// incidental server errors of loosely checked test cases.
+	FailOnServerError bool
+
+	// OnResult is called with every result once recorded, with its number, section and redacted captures, like to
+	// push it to StatsD, OpenTelemetry or a database during long runs. The captures are passed before CaptureOn and
+	// MaxRetainedBodyBytes drop them. The calls never overlap.
+	OnResult func(result ApiTestResult)
+
+	// DetectDuplicates warns about the test cases sharing their name or details with an earlier test case of the same
+	// section, which makes their failures ambiguous. With StrictDuplicates, such test cases fail instead.
+	DetectDuplicates bool
+	StrictDuplicates bool // StrictDuplicates fails the duplicate test cases, see DetectDuplicates.
+
+	// DetectDuplicateRequests warns about the test cases whose request is identical to the one of an earlier test
+	// case, with the same method, base URL, URL, path parameters, body and expected status, like copy-pasted test
+	// cases, whatever their names and details. They still run, and are counted in the summary.
+	DetectDuplicateRequests bool
+
+	// MaxServerConnections is the maximum count of concurrent connections of the Server, as a connection leak gate of
+	// the whole suite. When the observed peak exceeds it, DumpApiTestResult prints it as an error and exits with a
+	// failure status. It only observes the Server, not the servers of other base URLs.
+	MaxServerConnections int
+
+	// SuiteMaxP95 is the maximum 95th percentile of the times of all the test cases, as a latency gate of the whole
+	// suite. When exceeded, DumpApiTestResult prints it as an error and exits with a failure status.
+	SuiteMaxP95 time.Duration
+	SuiteMaxP99 time.Duration // SuiteMaxP99 is the maximum 99th percentile of the times, see SuiteMaxP95.
+
+	ForceRerun bool // ForceRerun runs the test cases already passed in the state of ResumeFrom.
+
+	IdempotencyKey func() string // IdempotencyKey generates the keys of AssertIdempotent, random UUIDs by default.
+
+	// MaxSuiteDuration is the time budget of the test cases, since the first Run. Once exceeded, Run stops sending the
+	// requests of its remaining test cases, which are recorded as not run instead, neither passed nor failed.
+	MaxSuiteDuration time.Duration
+
+	// ExitCode is the exit code of DumpApiTestResult when a test case fails, apart from the Soft ones, or the suite
+	// exceeds its SuiteMaxP95, SuiteMaxP99 or MaxServerConnections, 1 by default. TransportExitCode, when set, is the
+	// exit code when every failed test case failed to receive its response, like on a connection error, so that the
+	// pipelines can tell the network issues from the genuine failures. The exit code is 0 otherwise.
+	ExitCode          int
+	TransportExitCode int // TransportExitCode is the exit code when only transport errors failed, see ExitCode.
+
+	// ExitIgnoreTags is the tags of the test cases whose failure does not affect the exit code, like "experimental".
+	// Such test cases are reported, but handled like Soft test cases, and their failures are counted apart as ignored.
+	ExitIgnoreTags []string
+
+	// DefaultAssertions is the assertions evaluated against the response of every test case, before its Assertion,
+	// like RequireJsonErrorEnvelope.
+	DefaultAssertions []ApiTestAssertion
+
+	// CassettePath is the cassette file the responses are recorded to and replayed from, instead of sending the
+	// requests, to run the test cases offline. The requests are matched by method, path with query and body, so the
+	// cassette of a local Server replays whatever its port. CassetteMode is the mode of the cassette.
+	CassettePath string
+	CassetteMode ApiTestCassetteMode // CassetteMode is the mode of the cassette of CassettePath.
+
+	// CassetteRedact redacts the sensitive headers of the recorded responses like the captures, see RedactHeaders, so
+	// the cassette can be shared. The replayed responses then have "***" as their values, like for Set-Cookie, which
+	// fails the checks and captures of these values, like ExpectedCookieAttrs and CaptureHeaders.
+	CassetteRedact bool
+
+	// CaptureOn is when the captured requests and responses of the test cases, with their headers and bodies, are
+	// retained in the results and their reports. CaptureFailure, the default, retains them for the failed test cases
+	// only, so the passed ones stay lightweight while the failures remain debuggable.
+	CaptureOn ApiTestCaptureMode
+
+	// MaxRetainedBodyBytes is the maximum total size of the request and response bodies retained in the captures of
+	// the results, to bound the memory of large suites. Once exceeded, the bodies of the oldest results are dropped
+	// first, whatever their status, and a result whose bodies alone exceed it keeps none. The results whose bodies
+	// were dropped keep their other captures and have TestBodiesDropped set.
+	MaxRetainedBodyBytes int64
+
+	// TrackConnections counts the new and reused connections of the requests with httptrace, like to diagnose the
+	// keep-alive behavior, into the summary. It adds a small overhead to every request.
+	TrackConnections bool
+
+	token       string     // token is the cached token of the TokenProvider.
+	tokenExpiry time.Time  // tokenExpiry is the expiry time of token, if any.
+	tokenMutex  sync.Mutex // tokenMutex guards token.
+	section     string     // section is the name of the current section.
+	lastRequest time.Time  // lastRequest is the time the last request was sent.
+	rateMutex   sync.Mutex // rateMutex guards lastRequest.
+
+	variableMutex sync.Mutex // variableMutex guards Variables.
+
+	faults *faultInjector // faults is the fault injector wrapping the handler of the Server.
+
+	onResultMutex sync.Mutex // onResultMutex serializes the calls of OnResult.
+
+	perfBaseline map[string]time.Duration // perfBaseline is the loaded baseline times of the test cases.
+	perfMutex    sync.Mutex               // perfMutex guards perfBaseline.
+
+	suiteStart time.Time // suiteStart is the time the first Run started, the start of MaxSuiteDuration.
+
+	resumed map[string]bool // resumed is the state of the test cases loaded by ResumeFrom, passed or not by key.
+
+	connections     ApiTestConnectionStats // connections is the connections counted with TrackConnections.
+	connectionMutex sync.Mutex             // connectionMutex guards connections.
+
+	cassette      *cassette  // cassette is the loaded cassette of CassettePath.
+	cassetteMutex sync.Mutex // cassetteMutex guards cassette.
+
+	retainedBodies    []int64         // retainedBodies is the numbers of the results retaining bodies, oldest first.
+	retainedSizes     map[int64]int64 // retainedSizes is the size of the retained bodies, by result number.
+	retainedBodyBytes int64           // retainedBodyBytes is the total size of the retained bodies.
+
+	requestKeys map[string]int64 // requestKeys is the number of the first test case of each request, by its key.
+
+	serverConnections *serverConnections // serverConnections is the connections of the Server.
 }
 
 // ApiTestRequest is the request for a test case.
+//
+// When ContentType is not set, it is inferred from ReqBody: text/plain for a string, application/octet-stream for a
+// []byte, and application/json for any other value, which is sent as JSON. Without ReqBody, FormFields and Files are
+// sent as a multipart/form-data body, whose content type with its boundary replaces ContentType.
+//
+// The responses of HEAD requests have no body, so a HEAD test case checks only the status and headers of its
+// response: setting a body expectation, like ExpectedBody or CaptureJson, fails it, and AssertContentLength only
+// checks that the Content-Length header is valid.
 type ApiTestRequest struct {
+	Name           string      // Name is the short unique name of the test case, used to select and report it.
+	Soft           bool        // Soft records the failure of the test case without failing the run, like its exit code.
 	Details        string      // Details is the details like case of the API call.
-	ReqParam       interface{} // ReqParam is the path parameters of the API call.
-	ReqBody        interface{} // ReqBody is the body parameters of the API call.
+	ReqParam       interface{} // ReqParam is the path parameters of the API call, a string or a map of values.
+	ReqBody        interface{} // ReqBody is the body parameters of the API call, sent as is if string or []byte.
 	ApiUrl         string      // ApiUrl is the endpoint URL of the API call.
 	ApiMethod      string      // ApiMethod is the method of the API call.
-	ContentType    interface{} // ContentType is the content type of the API call.
+	ContentType    interface{} // ContentType is the content type of the API call, inferred from ReqBody if not set.
 	BearerToken    interface{} // BearerToken is the bearer token (like JWT token) of the API call.
-	ExpectedStatus interface{} // ExpectedStatus is the expected status code of the response.
+	ExpectedStatus interface{} // ExpectedStatus is the expected status code of the response, if available.
+	ExpectedBody   interface{} // ExpectedBody is the expected body of the response, compared as JSON when possible.
+
+	Tags []string // Tags is the tags of the test case, like "experimental", see ExitIgnoreTags.
+
+	// StatusMatcher is the predicate the status code of the response must satisfy, like StatusClass(2) for any 2xx
+	// status code, which overrides ExpectedStatus when set.
+	StatusMatcher func(status int) bool
+
+	// ExpectedStatusRange is the inclusive range of the expected status code, like {200, 299} for any 2xx status
+	// code, which overrides ExpectedStatus when set.
+	ExpectedStatusRange [2]int
+
+	// ExpectedBodySha256 is the expected hexadecimal SHA-256 of the response body, like of a binary download, checked
+	// without a copy of the expected body. FileSha256 returns the one of a file, like an uploaded file to check its
+	// echo.
+	ExpectedBodySha256 string
+
+	// ExpectedBodyFunc computes the ExpectedBody just before the request is sent, from a copy of the variables, like
+	// the captured ones, for the expectations depending on earlier responses or on the current time. It cannot be set
+	// with ExpectedBody.
+	ExpectedBodyFunc func(vars map[string]interface{}) interface{}
+
+	ExpectedBodyFile string            // ExpectedBodyFile is the file of the expected body, compared like ExpectedBody.
+	ExpectedTrailers map[string]string // ExpectedTrailers is the expected trailers of the response.
+
+	// NormalizeWhitespace compares the text bodies, like HTML or XML, with their runs of whitespace collapsed into a
+	// space and trimmed. The JSON bodies are still compared as JSON, whose whitespace is never significant.
+	NormalizeWhitespace bool
+
+	ExpectedHeaders        map[string]string // ExpectedHeaders is the expected headers of the response.
+	ExpectedHeaderPatterns map[string]string // ExpectedHeaderPatterns is the regular expressions the headers must match.
+	AssertContentLength    bool              // AssertContentLength checks the Content-Length header against the body.
+	ExpectedCharset        string            // ExpectedCharset is the expected charset of the Content-Type header.
+	ExpectedProto          string            // ExpectedProto is the expected protocol of the response, like "HTTP/2.0".
+	ForbiddenHeaders       []string          // ForbiddenHeaders is the headers the response must not have, like Server.
+	AssertValidUtf8        bool              // AssertValidUtf8 checks that the body is valid UTF-8.
+
+	ExpectedCacheControl string             // ExpectedCacheControl is the directives the Cache-Control header must have.
+	ExpectedCache        ApiTestCacheBounds // ExpectedCache is the expected bounds of the caching headers.
+
+	// ExpectedCookieAttrs is the expected cookies set by the response, by name. Only their attributes that are set are
+	// compared, like Value, Path, Domain, Secure, HttpOnly and SameSite.
+	ExpectedCookieAttrs map[string]http.Cookie
+
+	// ExpectedRedirectLocation is the expected Location of a 3xx response, which is not followed. The relative
+	// locations are resolved against the URL of the request before comparing them. ExpectedRedirectPattern is a
+	// regular expression the Location must match instead, as is or resolved.
+	ExpectedRedirectLocation string
+	ExpectedRedirectPattern  string // ExpectedRedirectPattern is the regular expression of the expected Location.
+
+	// ExpectedRedirectChain is the expected URLs of the followed redirects in order, like the HTTPS and then the
+	// canonical URL of a page. The relative URLs are resolved against the URL of the response before comparing them.
+	// ExpectedRedirectChainPatterns is the regular expressions the URLs must match instead, one per redirect.
+	ExpectedRedirectChain         []string
+	ExpectedRedirectChainPatterns []string // ExpectedRedirectChainPatterns is the regular expressions of the URLs.
+
+	// ExpectedAuthChallenge is the expected challenge of the WWW-Authenticate header of a 401 response, like
+	// `Bearer realm="api", error="invalid_token"`. The response must have a challenge of its scheme with all its
+	// parameters.
+	ExpectedAuthChallenge string
+
+	// AssertRateLimitHeaders checks that the response has X-RateLimit-Limit and X-RateLimit-Remaining headers with
+	// non-negative integers, the remaining count not exceeding the limit. See AssertRateLimit for their decrements.
+	AssertRateLimitHeaders bool
+
+	ExpectedBodyOneOf []interface{} // ExpectedBodyOneOf is the possible bodies of the response, compared like ExpectedBody.
+
+	// ExpectedSchemaOneOf is the JSON schema files the body must be valid against at least one of, like the variants
+	// of a polymorphic response, with the JsonSchemaValidator of the ApiTest.
+	ExpectedSchemaOneOf []string
+
+	Assertion       ApiTestAssertion // Assertion is an assertion expression evaluated against the response.
+	ExpectValidJson bool             // ExpectValidJson is whether the body must be valid JSON, whatever its content.
+
+	ExpectedJsonFields       map[string]interface{}   // ExpectedJsonFields is the expected values at JSON paths of the body.
+	ExpectedJsonFieldsApprox map[string]ApiTestApprox // ExpectedJsonFieldsApprox is the expected numbers at JSON paths.
+
+	// ExpectedJsonPath is the expected results of full JSONPath expressions evaluated against the JSON body, like
+	// "$.store.book[?(@.price<10)].title", with the JsonPathEvaluator of the ApiTest. The results are compared as JSON.
+	ExpectedJsonPath map[string]interface{}
+
+	// RequireNonNull is the JSON paths of the body that must be present and not null, like the fields required by the
+	// contract of the API. With RequireNonEmpty, their values must not be empty strings, arrays or objects either.
+	RequireNonNull  []string
+	RequireNonEmpty bool // RequireNonEmpty rejects the empty values at the paths of RequireNonNull.
+
+	// ExpectedBodySubset is the structure the JSON body must contain, like ExpectedBody but ignoring the extra keys of
+	// its objects, at any depth. The arrays must have the same length, with their items compared the same way.
+	ExpectedBodySubset interface{}
+
+	// ExpectedShape is a value, like a struct with validation tags, whose type the body must decode into. The decoded
+	// value is then checked by the Validator of the ApiTest.
+	ExpectedShape interface{}
+
+	ExpectedXsd        string            // ExpectedXsd is the XML schema file the body is validated against.
+	ExpectedOrder      *ApiTestOrder     // ExpectedOrder is the expected order of a JSON array of the body.
+	ExpectedErrorField ApiTestErrorField // ExpectedErrorField is the expected field of the body of a non-2xx response.
+
+	Headers           map[string]string // Headers is the additional headers of the API call.
+	IfNoneMatch       string            // IfNoneMatch is the If-None-Match header, like a captured ETag.
+	IfModifiedSince   string            // IfModifiedSince is the If-Modified-Since header, like a captured Last-Modified.
+	ExpectNotModified bool              // ExpectNotModified is whether the response must be 304 Not Modified.
+	ChunkedBody       bool              // ChunkedBody sends the body with chunked encoding instead of a Content-Length.
+	QueryStyle        ApiTestQueryStyle // QueryStyle is the encoding style of the values of a url.Values ReqParam.
+
+	// RawHeaders is the additional headers of the API call sent with their names as is, like "X-CUSTOM-Header",
+	// instead of canonicalized like Headers. It is only for the servers picky about the case of header names, most
+	// test cases should use Headers.
+	RawHeaders map[string]string
+
+	// MaxFirstByteTime is the maximum time, since the request was sent, before the first byte of the body is read,
+	// and MaxChunkInterval the maximum time between two chunks of the body, like of a streamed or SSE response. They
+	// catch servers buffering their response instead of streaming it.
+	MaxFirstByteTime time.Duration
+	MaxChunkInterval time.Duration // MaxChunkInterval is the maximum time between two chunks, see MaxFirstByteTime.
+
+	// ExpectCompressed sends the request with "Accept-Encoding: gzip", unless set in Headers, and checks that the
+	// response is gzip encoded and smaller than its decompressed body, which is then checked like any other body.
+	ExpectCompressed bool
+
+	// AssertIdempotent sends the request a second time with the same Idempotency-Key header, generated by the
+	// IdempotencyKey of the ApiTest unless set in Headers, and checks that both responses have the same status and
+	// body, compared as JSON when possible.
+	AssertIdempotent bool
+
+	// ExpectedTlsMinVersion is the minimum TLS version of the connection, like tls.VersionTLS12, and
+	// AssertCertNotExpiringWithin the minimum time before the server certificate expires, for HTTPS requests. They
+	// catch weak TLS configurations and certificates due for rotation.
+	ExpectedTlsMinVersion       uint16
+	AssertCertNotExpiringWithin time.Duration // AssertCertNotExpiringWithin is the minimum time before expiry.
+
+	// BodyReadTimeout is the maximum time reading the body of the response takes, once its headers are received. It
+	// tells slow bodies, like trickling ones, from slow headers.
+	BodyReadTimeout time.Duration
+
+	FormFields map[string]string // FormFields is the form fields of the multipart body, written before Files.
+	Files      []ApiTestFile     // Files is the files of the multipart body, written in order.
+
+	// BodyTemplate is a text/template rendered into the body instead of ReqBody, like to generate the items of a bulk
+	// insert. It is executed against the variables overridden by TemplateData, and sent as application/json when it
+	// renders valid JSON, or else as text/plain, unless ContentType is set.
+	BodyTemplate string
+	TemplateData map[string]interface{} // TemplateData is the data of BodyTemplate, overriding the variables.
+
+	// CaptureHeaders is the response headers captured into variables, by variable name, when the test case passes.
+	// The variables can then be used as "{{name}}" in the URL, path parameters, headers and body of the next test
+	// cases.
+	CaptureHeaders map[string]string
+
+	CaptureJson map[string]string // CaptureJson is the JSON paths of the body captured into variables, by variable name.
+
+	// BindResponse is a pointer, like to a struct, the JSON body of the response is decoded into, so its typed fields
+	// can be inspected after the test case. It is only bound when the response has a JSON content type.
+	BindResponse interface{}
+
+	// AfterAssert is a hook run after the response of the test case is checked, like to check that a record was
+	// created in the database. A non-nil error fails the test case. It only runs when the test case passes, unless
+	// AfterAssertAlways is set.
+	AfterAssert       func() error
+	AfterAssertAlways bool // AfterAssertAlways runs AfterAssert even when the test case fails.
 }
 
 var (
@@ -66,6 +490,10 @@ var (
 	ContentTypeHtml2 = "text/html; charset=utf-8"          // ContentTypeHtml2 is for APIs with Html2 content.
 )
 
+// DefaultRedactHeaders is the list of sensitive headers whose values are replaced by "***" in dumps and recorded
+// results. Use ApiTest.RedactHeaders to redact more headers.
+var DefaultRedactHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
 // InitApiTest function initializes an instance of the ApiTest struct and returns a pointer to it.
 //
 // Example usage:
@@ -78,43 +506,217 @@ var (
 // T.DumpApiTestResult(true)
 // ```
 func InitApiTest() *ApiTest {
-	mux := http.NewServeMux()
-	httptest.NewServer(mux)
+	return InitApiTestWithHandler(http.NewServeMux())
+}
+
+// InitApiTestWithHandler function initializes an instance of the ApiTest struct whose server serves the given
+// handler, like the router of the whole application, and returns a pointer to it. ServerMux is only set when the
+// handler is a *http.ServeMux.
+//
+// Example usage:
+//
+// ```
+// var T *ApiTest
+// T = InitApiTestWithHandler(app.Router())
+// // More process...
+// T.DumpApiTestResult(true)
+// ```
+func InitApiTestWithHandler(handler http.Handler) *ApiTest {
+	mux, _ := handler.(*http.ServeMux)
+	faults := &faultInjector{handler: handler}
+	connections := &serverConnections{}
+
+	server := httptest.NewUnstartedServer(faults)
+	server.Config.ConnState = connections.connState
+	server.Start()
 
 	return &ApiTest{
 		Tests:       0,
 		PassedTests: 0,
 		FailedTests: 0,
 		Result:      make(map[int64]ApiTestResult),
-		Server:      httptest.NewServer(mux),
+		Server:      server,
 		ServerMux:   mux,
+		Variables:   make(map[string]interface{}),
+		faults:      faults,
+
+		serverConnections: connections,
 	}
 }
 
-// generateApiUrl function takes a httptest.Server and a getPath string as inputs and returns a string that
-// represents the complete URL for an API call.
-func generateApiUrl(server *httptest.Server, getPath string) string {
-	return server.URL + getPath
+// generateApiUrl function takes a base URL and a getPath string as inputs and returns a string that represents the
+// complete URL for an API call.
+func generateApiUrl(baseUrl string, getPath string) string {
+	return baseUrl + getPath
+}
+
+// baseUrl function returns the base URL of the API calls, the BaseUrl when set and the URL of the server otherwise.
+func (h *ApiTest) baseUrl() string {
+	if h.BaseUrl != "" {
+		return h.BaseUrl
+	}
+
+	return h.Server.URL
 }
 
 // addTestResult function adds a test result to the ApiTest struct.
-func (h *ApiTest) addTestResult(description string, reqError interface{}, isTestPassed bool, processTime time.Duration) {
+func (h *ApiTest) addTestResult(result ApiTestResult) {
+	h.redactResult(&result)
+	h.ignoreTags(&result)
+
+	result.TestSection = h.section
+
 	h.Tests++
+	result.TestNumber = h.Tests
 
-	if isTestPassed {
+	if h.DetectDuplicates || h.StrictDuplicates {
+		h.checkDuplicate(&result)
+	}
+
+	if h.DetectDuplicateRequests {
+		h.checkDuplicateRequest(&result)
+	}
+
+	if result.TestNotRun {
+		h.NotRunTests++
+	} else if result.TestStatus {
 		h.PassedTests++
 	} else {
 		h.FailedTests++
+
+		if result.TestSoft {
+			h.SoftFailed++
+		}
+	}
+
+	h.notifyResult(result)
+
+	h.retainCapture(&result)
+	h.retainBodies(&result)
+	h.Result[h.Tests] = result
+}
+
+// notifyResult function calls the OnResult callback with a recorded result, one result at a time.
+func (h *ApiTest) notifyResult(result ApiTestResult) {
+	if h.OnResult == nil {
+		return
+	}
+
+	h.onResultMutex.Lock()
+	defer h.onResultMutex.Unlock()
+
+	h.OnResult(result)
+}
+
+// checkDuplicate function records the earlier test case of the same section sharing the name or details of a test
+// result, and warns about it, or fails the test case with StrictDuplicates.
+func (h *ApiTest) checkDuplicate(result *ApiTestResult) {
+	for _, number := range h.resultNumbers() {
+		earlier := h.Result[number]
+		if earlier.TestSection != result.TestSection {
+			continue
+		}
+
+		sameName := result.TestName != "" && earlier.TestName == result.TestName
+		if sameName || earlier.TestDescription == result.TestDescription {
+			result.TestDuplicateOf = number
+			break
+		}
+	}
+
+	if result.TestDuplicateOf == 0 {
+		return
+	}
+
+	message := fmt.Sprintf("duplicate of test case %d", result.TestDuplicateOf)
+	if !h.StrictDuplicates {
+		h.logger().Warn(message, "number", result.TestNumber, "name", result.TestName,
+			"description", result.TestDescription)
+		return
+	}
+
+	if result.TestStatus {
+		result.TestStatus = false
+		result.TestError = newTestError(ErrorAssertion, message)
+	}
+}
+
+// checkDuplicateRequest function warns when the request of a result is identical to the one of an earlier test case.
+func (h *ApiTest) checkDuplicateRequest(result *ApiTestResult) {
+	if result.request == nil {
+		return
+	}
+
+	httpReq := result.request
+	key, err := json.Marshal([]interface{}{strings.ToUpper(httpReq.ApiMethod), result.baseUrl, httpReq.ApiUrl,
+		httpReq.ReqParam, httpReq.ReqBody, httpReq.BodyTemplate, httpReq.ExpectedStatus})
+	if err != nil {
+		return
+	}
+
+	if h.requestKeys == nil {
+		h.requestKeys = make(map[string]int64)
+	}
+
+	number, isPresent := h.requestKeys[string(key)]
+	if !isPresent {
+		h.requestKeys[string(key)] = result.TestNumber
+		return
+	}
+
+	result.TestDuplicateRequestOf = number
+	h.logger().Warn(fmt.Sprintf("duplicate request of test case %d", number), "number", result.TestNumber,
+		"name", result.TestName, "description", result.TestDescription)
+}
+
+// redactResult function redacts the captured headers and trailers of a test result.
+func (h *ApiTest) redactResult(result *ApiTestResult) {
+	if result.TestRequest != nil {
+		result.TestRequest.Header = h.redactHeader(result.TestRequest.Header)
 	}
 
-	h.Result[h.Tests] = ApiTestResult{
-		TestStatus:      isTestPassed,
-		TestDescription: description,
-		TestError:       reqError,
-		TestTime:        processTime,
+	if result.TestResponse != nil {
+		result.TestResponse.Header = h.redactHeader(result.TestResponse.Header)
+		result.TestResponse.Trailer = h.redactHeader(result.TestResponse.Trailer)
+	}
+}
+
+// ignoreTags function makes a result Soft when one of its tags is in ExitIgnoreTags.
+func (h *ApiTest) ignoreTags(result *ApiTestResult) {
+	for _, tag := range result.TestTags {
+		if slices.Contains(h.ExitIgnoreTags, tag) {
+			result.TestSoft, result.TestIgnored = true, true
+			return
+		}
 	}
 }
 
+// Section function starts a new section of test cases, the following test cases are grouped under its name in the
+// reports.
+//
+// Example usage:
+//
+// ```
+// T.Section("Users")
+// T.CreateTest(...)
+// T.Section("Orders")
+// T.CreateTest(...)
+// ```
+func (h *ApiTest) Section(name string) {
+	h.section = name
+}
+
+// resultNumbers function returns the numbers of the recorded test results in ascending order.
+func (h *ApiTest) resultNumbers() []int64 {
+	numbers := make([]int64, 0, len(h.Result))
+	for number := range h.Result {
+		numbers = append(numbers, number)
+	}
+
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+	return numbers
+}
+
 // GeneratePathParam function generates a query string from a map of path parameters.
 func GeneratePathParam(getParam map[string]string) string {
 	if len(getParam) == 0 {
@@ -133,29 +735,43 @@ func GeneratePathParam(getParam map[string]string) string {
 
 // CreateTest function creates a new test case for an API call.
 func (h *ApiTest) CreateTest(httpReq ApiTestRequest) {
-	var reqParam string
-	var reqBody io.Reader
+	h.createTest(httpReq, 0)
+}
 
-	if httpReq.ReqParam != nil {
-		reqParam = httpReq.ReqParam.(string)
-	} else {
-		reqParam = ""
+// createTest function creates a new test case at a 1-based index of the requests of a run, or 0 for a single test
+// case.
+func (h *ApiTest) createTest(httpReq ApiTestRequest, index int) {
+	if h.skipResumed(httpReq) {
+		return
 	}
 
-	if httpReq.ReqBody != nil {
-		jsonBytes, err := json.Marshal(httpReq.ReqBody)
-		if err != nil {
-			h.addTestResult(httpReq.Details, err.Error(), false, 0)
-			return
-		}
+	result := h.runTest(httpReq)
+	result.request, result.baseUrl = &httpReq, h.BaseUrl
+	result.TestIndex = index
 
-		reqBody = bytes.NewBufferString(string(jsonBytes))
+	h.addTestResult(result)
+}
+
+// encodeBody function encodes the body of a request and infers its content type: a string is sent as is as
+// text/plain, a []byte as is as application/octet-stream, and any other value as application/json.
+func encodeBody(body interface{}) ([]byte, string, error) {
+	switch value := body.(type) {
+	case string:
+		return []byte(value), ContentTypeText, nil
+	case []byte:
+		return value, "application/octet-stream", nil
+	default:
+		jsonBytes, err := json.Marshal(value)
+		return jsonBytes, ContentTypeJson, err
 	}
+}
 
-	req, err := http.NewRequest(httpReq.ApiMethod, generateApiUrl(h.Server, httpReq.ApiUrl)+reqParam, reqBody)
+// newRequest function creates the HTTP request of a test case. A non-empty token is used as bearer token when the
+// test case has no explicit BearerToken.
+func (h *ApiTest) newRequest(httpReq ApiTestRequest, reqUrl string, reqBody []byte, token string) (*http.Request, error) {
+	req, err := http.NewRequest(httpReq.ApiMethod, reqUrl, bytes.NewReader(reqBody))
 	if err != nil {
-		h.addTestResult(httpReq.Details, err.Error(), false, 0)
-		return
+		return nil, err
 	}
 
 	if httpReq.ContentType != nil {
@@ -164,57 +780,635 @@ func (h *ApiTest) CreateTest(httpReq ApiTestRequest) {
 
 	if httpReq.BearerToken != nil {
 		req.Header.Set("Authorization", "Bearer "+httpReq.BearerToken.(string))
+	} else if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	for name, value := range httpReq.Headers {
+		req.Header.Set(name, value)
+	}
+
+	for name, value := range httpReq.RawHeaders {
+		req.Header[name] = []string{value}
+	}
+
+	if httpReq.ExpectCompressed && req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+
+	if httpReq.IfNoneMatch != "" {
+		req.Header.Set("If-None-Match", httpReq.IfNoneMatch)
+	}
+
+	if httpReq.IfModifiedSince != "" {
+		req.Header.Set("If-Modified-Since", httpReq.IfModifiedSince)
+	}
+
+	if httpReq.ChunkedBody && len(reqBody) > 0 {
+		req.ContentLength = -1
+		req.TransferEncoding = []string{"chunked"}
+	}
+
+	if httpReq.ExpectedRedirectLocation != "" || httpReq.ExpectedRedirectPattern != "" {
+		req = req.WithContext(context.WithValue(req.Context(), noRedirectKey{}, true))
+	}
+
+	return req, nil
+}
+
+// noRedirectKey is the context key of the requests whose redirects are not followed.
+type noRedirectKey struct{}
+
+// providedToken function returns the cached bearer token of the TokenProvider or OAuth2, fetching a fresh one when
+// there is no cached token, when it expired or when refresh is true.
+func (h *ApiTest) providedToken(refresh bool) (string, error) {
+	h.tokenMutex.Lock()
+	defer h.tokenMutex.Unlock()
+
+	expired := !h.tokenExpiry.IsZero() && time.Now().After(h.tokenExpiry)
+	if h.token != "" && !refresh && !expired {
+		return h.token, nil
+	}
+
+	if h.OAuth2 != nil {
+		token, expiry, err := h.fetchOAuth2Token()
+		if err != nil {
+			h.token = ""
+			return "", fmt.Errorf("oauth2: %w", err)
+		}
+
+		h.token, h.tokenExpiry = token, expiry
+		return token, nil
+	}
+
+	token, err := h.TokenProvider()
+	if err != nil {
+		h.token = ""
+		return "", fmt.Errorf("token provider: %w", err)
+	}
+
+	h.token = token
+	return token, nil
+}
+
+// output function returns the writer of the report.
+func (h *ApiTest) output() io.Writer {
+	if h.Output == nil {
+		return os.Stdout
+	}
+
+	return h.Output
+}
+
+// redactHeader function returns a copy of the headers with the values of sensitive headers replaced by "***".
+func (h *ApiTest) redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+
+	isRedacted := func(name string) bool {
+		matches := func(redactedName string) bool { return strings.EqualFold(name, redactedName) }
+		return slices.ContainsFunc(DefaultRedactHeaders, matches) || slices.ContainsFunc(h.RedactHeaders, matches)
+	}
+
+	for name, values := range redacted {
+		if isRedacted(name) {
+			for i := range values {
+				values[i] = "***"
+			}
+		}
+	}
+
+	return redacted
+}
+
+// waitRateLimit function blocks until the RateLimit allows sending a new request.
+func (h *ApiTest) waitRateLimit() {
+	h.rateMutex.Lock()
+	defer h.rateMutex.Unlock()
+
+	if h.RateLimit > 0 && !h.lastRequest.IsZero() {
+		if wait := h.RateLimit - time.Since(h.lastRequest); wait > 0 {
+			time.Sleep(wait)
+		}
+	}
+
+	h.lastRequest = time.Now()
+}
+
+// do function sends a request once the RateLimit allows it, dumping it first when DumpRequests is set.
+func (h *ApiTest) do(req *http.Request, reqBody []byte) (*http.Response, error) {
+	h.waitRateLimit()
+
+	if h.DisableKeepAlives {
+		req.Close = true
+	}
+
+	if h.TrackConnections {
+		req = h.traceConnections(req)
+	}
+
+	if h.DumpRequests {
+		dumpReq := req.Clone(req.Context())
+		dumpReq.Header = h.redactHeader(req.Header)
+		dumpReq.Body = io.NopCloser(bytes.NewReader(reqBody))
+
+		if dump, err := httputil.DumpRequestOut(dumpReq, true); err == nil {
+			h.logger().Debug(string(dump))
+		}
+	}
+
+	client := h.client()
+	if req.Context().Value(noRedirectKey{}) != nil {
+		noRedirectClient := *client
+		noRedirectClient.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+		client = &noRedirectClient
+	}
+
+	if h.CassettePath != "" {
+		transport, err := h.cassetteTransport(client.Transport)
+		if err != nil {
+			return nil, err
+		}
+
+		cassetteClient := *client
+		cassetteClient.Transport = transport
+		client = &cassetteClient
+	}
+
+	return client.Do(req)
+}
+
+// client function returns the client sending the requests.
+func (h *ApiTest) client() *http.Client {
+	if h.Client == nil {
+		return http.DefaultClient
+	}
+
+	return h.Client
+}
+
+// send function sends the request of a test case, retrying it up to Retries times on transport errors and on the
+// status codes of RetryOnStatus. The retries are counted in the result.
+func (h *ApiTest) send(httpReq ApiTestRequest, reqUrl string, reqBody []byte, token string,
+	result *ApiTestResult) (*http.Request, *http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := h.newRequest(httpReq, reqUrl, reqBody, token)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resp, respErr := h.do(req, reqBody)
+		if attempt >= h.Retries {
+			return req, resp, respErr
+		}
+
+		delay := h.RetryDelay
+		if respErr == nil {
+			if !slices.Contains(h.RetryOnStatus, resp.StatusCode) {
+				return req, resp, nil
+			}
+
+			if retryAfter, isPresent := parseRetryAfter(resp); isPresent {
+				delay = retryAfter
+			}
+
+			resp.Body.Close()
+		}
+
+		result.TestRetries++
+		time.Sleep(delay)
+	}
+}
+
+// parseRetryAfter function returns the delay of the Retry-After header of a 429 or 503 response, given either in
+// seconds or as an HTTP date.
+func parseRetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+// dumpResponse function prints a received response with its already read body.
+func (h *ApiTest) dumpResponse(resp *http.Response, respBody []byte) {
+	dumpResp := *resp
+	dumpResp.Header = h.redactHeader(resp.Header)
+	dumpResp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	if dump, err := httputil.DumpResponse(&dumpResp, true); err == nil {
+		h.logger().Debug(string(dump))
+	}
+}
+
+// runTest function runs a test case, followed by its AfterAssert hook, and returns its result.
+func (h *ApiTest) runTest(httpReq ApiTestRequest) ApiTestResult {
+	result := h.sendTest(httpReq)
+	if httpReq.AfterAssert == nil || (!result.TestStatus && !httpReq.AfterAssertAlways) {
+		return result
+	}
+
+	if err := httpReq.AfterAssert(); err != nil {
+		if result.TestStatus {
+			result.TestStatus = false
+			result.TestError = responseError(ErrorAssertion, "after assert: "+err.Error(), result.TestResponse)
+		} else {
+			result.TestError.Message += "; after assert: " + err.Error()
+		}
+	}
+
+	return result
+}
+
+// sendTest function sends the request of a test case and returns its result.
+func (h *ApiTest) sendTest(httpReq ApiTestRequest) ApiTestResult {
+	var reqBody []byte
+
+	result := ApiTestResult{TestName: httpReq.Name, TestDescription: httpReq.Details, TestSoft: httpReq.Soft,
+		TestTags: httpReq.Tags}
+
+	httpReq, err := h.interpolateRequest(httpReq)
+	if err != nil {
+		result.TestError = newTestError(ErrorSetup, err.Error())
+		return result
+	}
+
+	if err := checkHeadRequest(httpReq); err != nil {
+		result.TestError = newTestError(ErrorSetup, err.Error())
+		return result
+	}
+
+	if httpReq, err = h.computeExpectedBody(httpReq); err != nil {
+		result.TestError = newTestError(ErrorSetup, err.Error())
+		return result
+	}
+
+	if httpReq.AssertIdempotent {
+		httpReq.Headers = h.withIdempotencyKey(httpReq.Headers)
+	}
+
+	reqParam, err := generateReqParam(httpReq.ReqParam, httpReq.QueryStyle)
+	if err != nil {
+		result.TestError = newTestError(ErrorSetup, err.Error())
+		return result
+	}
+
+	if httpReq.BodyTemplate != "" {
+		renderedBody, contentType, err := h.renderBodyTemplate(httpReq)
+		if err != nil {
+			result.TestError = newTestError(ErrorSetup, err.Error())
+			return result
+		}
+
+		reqBody = renderedBody
+		if httpReq.ContentType == nil {
+			httpReq.ContentType = contentType
+		}
+	} else if httpReq.ReqBody != nil {
+		encodedBody, contentType, err := encodeBody(httpReq.ReqBody)
+		if err != nil {
+			result.TestError = newTestError(ErrorSetup, err.Error())
+			return result
+		}
+
+		reqBody = encodedBody
+		if httpReq.ContentType == nil {
+			httpReq.ContentType = contentType
+		}
+	} else if len(httpReq.FormFields) > 0 || len(httpReq.Files) > 0 {
+		encodedBody, contentType, err := encodeMultipart(httpReq.FormFields, httpReq.Files)
+		if err != nil {
+			result.TestError = newTestError(ErrorSetup, err.Error())
+			return result
+		}
+
+		reqBody = encodedBody
+		httpReq.ContentType = contentType
+	}
+
+	reqUrl := generateApiUrl(h.baseUrl(), httpReq.ApiUrl) + reqParam
+
+	var token string
+	useProvider := httpReq.BearerToken == nil && (h.TokenProvider != nil || h.OAuth2 != nil)
+	if useProvider {
+		var err error
+		if token, err = h.providedToken(false); err != nil {
+			result.TestError = newTestError(ErrorSetup, err.Error())
+			return result
+		}
+	}
+
+	if _, err := h.newRequest(httpReq, reqUrl, reqBody, token); err != nil {
+		result.TestError = newTestError(ErrorSetup, err.Error())
+		return result
 	}
 
 	startTime := time.Now()
-	resp, respErr := http.DefaultClient.Do(req)
-	endTime := time.Now()
+	req, resp, respErr := h.send(httpReq, reqUrl, reqBody, token, &result)
+
+	if respErr == nil && useProvider && resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+
+		var err error
+		if token, err = h.providedToken(true); err != nil {
+			result.setTime(startTime)
+			result.TestError = newTestError(ErrorSetup, err.Error())
+			return result
+		}
+
+		req, resp, respErr = h.send(httpReq, reqUrl, reqBody, token, &result)
+	}
+
+	result.TestRequest = &ApiTestRequestCapture{
+		Method:           req.Method,
+		Url:              req.URL.String(),
+		Header:           req.Header.Clone(),
+		Body:             reqBody,
+		TransferEncoding: req.TransferEncoding,
+	}
 
 	if respErr != nil {
-		h.addTestResult(httpReq.Details, respErr.Error(), false, endTime.Sub(startTime))
-		return
+		result.setTime(startTime)
+		result.TestError = newTestError(ErrorTransport, respErr.Error())
+		return result
 	}
 
-	if resp.StatusCode != httpReq.ExpectedStatus.(int) {
-		h.addTestResult(httpReq.Details, resp, false, endTime.Sub(startTime))
-		return
+	var respBody []byte
+	var chunkTimes []time.Duration
+	var readErr error
+
+	var readTimedOut atomic.Bool
+	if httpReq.BodyReadTimeout > 0 {
+		timer := time.AfterFunc(httpReq.BodyReadTimeout, func() {
+			readTimedOut.Store(true)
+			resp.Body.Close()
+		})
+
+		defer timer.Stop()
+	}
+
+	if httpReq.MaxFirstByteTime > 0 || httpReq.MaxChunkInterval > 0 {
+		respBody, chunkTimes, readErr = readChunks(resp.Body, startTime)
+	} else {
+		respBody, readErr = io.ReadAll(resp.Body)
+	}
+
+	resp.Body.Close()
+	if readErr != nil && readTimedOut.Load() {
+		readErr = fmt.Errorf("response body read timed out after %s", httpReq.BodyReadTimeout)
+	}
+
+	var compressErr error
+	if readErr == nil && httpReq.ExpectCompressed {
+		if decompressed, err := decompressBody(resp, respBody); err != nil {
+			compressErr = err
+		} else {
+			respBody = decompressed
+		}
+	}
+
+	result.setTime(startTime)
+
+	if h.DumpResponses {
+		h.dumpResponse(resp, respBody)
+	}
+
+	result.TestResponse = &ApiTestResponseCapture{
+		Status:     resp.StatusCode,
+		Proto:      resp.Proto,
+		Header:     resp.Header.Clone(),
+		Body:       respBody,
+		Trailer:    resp.Trailer.Clone(),
+		Url:        resp.Request.URL.String(),
+		ChunkTimes: chunkTimes,
+		Tls:        captureTls(resp.TLS),
+	}
+
+	if httpReq.ExpectedRedirectChain != nil || httpReq.ExpectedRedirectChainPatterns != nil {
+		result.TestResponse.Redirects = redirectChain(resp)
+	}
+
+	if readErr != nil {
+		if err := checkContentLength(h, httpReq, result.TestResponse); err != nil {
+			readErr = fmt.Errorf("%w: %w", readErr, err)
+		}
+
+		result.TestError = responseError(ErrorTransport, readErr.Error(), result.TestResponse)
+		return result
+	}
+
+	if compressErr != nil {
+		result.TestError = responseError(ErrorAssertion, compressErr.Error(), result.TestResponse)
+		return result
 	}
 
-	h.addTestResult(httpReq.Details, nil, true, endTime.Sub(startTime))
-	return
+	if err := checkStatus(httpReq, resp); err != nil {
+		result.TestError = responseError(ErrorStatus, err.Error(), result.TestResponse)
+		return result
+	}
+
+	for _, check := range responseChecks {
+		if err := check(h, httpReq, result.TestResponse); err != nil {
+			result.TestError = responseError(ErrorAssertion, err.Error(), result.TestResponse)
+			return result
+		}
+	}
+
+	if httpReq.AssertIdempotent {
+		if err := h.checkIdempotent(httpReq, reqUrl, reqBody, token, &result); err != nil {
+			result.TestError = responseError(ErrorAssertion, err.Error(), result.TestResponse)
+			return result
+		}
+	}
+
+	if err := h.captureVariables(httpReq, result.TestResponse); err != nil {
+		result.TestError = responseError(ErrorAssertion, err.Error(), result.TestResponse)
+		return result
+	}
+
+	if err := h.checkPerfBaseline(httpReq, result.TestTime); err != nil {
+		result.TestError = responseError(ErrorAssertion, err.Error(), result.TestResponse)
+		return result
+	}
+
+	result.TestStatus = true
+	return result
 }
 
-// DumpApiTestResult function prints the result of the API test cases in to the terminal.
-func (h *ApiTest) DumpApiTestResult(needExit bool) {
-	defer h.Server.Close()
+// StatusClass function returns a StatusMatcher satisfied by the status codes of a class, like 2 for any 2xx status
+// code.
+//
+// Example usage:
+//
+// ```
+// T.CreateTest(ApiTestRequest{Details: "Create user", ApiUrl: "/users", ApiMethod: http.MethodPost,
+// StatusMatcher: StatusClass(2)})
+// ```
+func StatusClass(class int) func(status int) bool {
+	return func(status int) bool {
+		return status/100 == class
+	}
+}
+
+// checkStatus function checks that the status code of a response is the expected status of a test case.
+func checkStatus(httpReq ApiTestRequest, resp *http.Response) error {
+	status := resp.StatusCode
 
-	fmt.Printf("\nAPI Test Result:\n\n")
-	fmt.Printf("┌──────┬──────────┬─────────────────┬─────────────────────--------------►\n")
-	fmt.Printf("│ %-4s │ %-8s │ %-15s │ %s\n", "No", "Status", "Time", "Description")
-	fmt.Printf("├──────┼──────────┼─────────────────┼─────────────────────--------------►\n")
+	if httpReq.StatusMatcher != nil {
+		if !httpReq.StatusMatcher(status) {
+			return errors.New("status code " + resp.Status + " does not satisfy StatusMatcher")
+		}
 
-	for i, result := range h.Result {
-		fmt.Printf("│ %-4d │ %-8s │ %-15s │ %s", i, strconv.FormatBool(result.TestStatus),
-			result.TestTime, result.TestDescription)
+		return nil
+	}
 
-		if result.TestError != nil {
-			fmt.Print("\u001B[1;31m [ Error:\033[0;0m ", result.TestError, "\u001B[1;31m ]\u001B[0;0m")
+	if statusRange := httpReq.ExpectedStatusRange; statusRange != [2]int{} {
+		if status < statusRange[0] || status > statusRange[1] {
+			return fmt.Errorf("status code %s not in range %d-%d", resp.Status, statusRange[0], statusRange[1])
 		}
 
-		fmt.Printf("\n")
+		return nil
+	}
+
+	expected := status
+	if httpReq.ExpectNotModified {
+		expected = http.StatusNotModified
+	} else if httpReq.ExpectedStatus != nil {
+		expected = httpReq.ExpectedStatus.(int)
+	}
+
+	if status != expected {
+		return errors.New("unexpected status code " + resp.Status)
 	}
 
-	fmt.Printf("└──────┴──────────┴─────────────────┴─────────────────────--------------►\n")
+	return nil
+}
 
-	fmt.Printf("\n%-40s : \033[1;36m%d\033[0;0m\n", "Total white box API test cases", h.Tests)
-	fmt.Printf("%-40s : \033[1;32m%d/%d\033[0;0m\n", "Total passed white box API test cases", h.PassedTests, h.Tests)
-	fmt.Printf("%-40s : \033[1;31m%d/%d\033[0;0m\n\n", "Total failed white box API test cases", h.FailedTests, h.Tests)
+// DumpApiTestResult function prints the result of the API test cases in to the terminal.
+func (h *ApiTest) DumpApiTestResult(needExit bool) {
+	if err := h.Report(h.output(), ApiTestTableReporter{}); err != nil {
+		h.logger().Error("cannot print the result", "error", err)
+	}
+
+	if h.UpdatePerfBaseline {
+		if err := h.SavePerfBaseline(); err != nil {
+			h.logger().Error("cannot save the performance baseline", "error", err)
+		}
+	}
+
+	h.Close()
 
 	if needExit {
-		if h.FailedTests > 0 {
-			os.Exit(1)
+		os.Exit(h.exitCode())
+	}
+}
+
+// exitCode function returns the exit code of the result of the API test cases, see ExitCode.
+func (h *ApiTest) exitCode() int {
+	failed := h.FailedTests > h.SoftFailed
+	suiteErr := h.Summary().suiteError()
+
+	switch {
+	case !failed && suiteErr == nil:
+		return 0
+	case failed && suiteErr == nil && h.TransportExitCode != 0 && h.onlyTransportFailures():
+		return h.TransportExitCode
+	case h.ExitCode != 0:
+		return h.ExitCode
+	default:
+		return 1
+	}
+}
+
+// onlyTransportFailures function reports whether every failed test case, apart from the Soft ones, failed with a
+// transport error, like on a connection error or a timeout.
+func (h *ApiTest) onlyTransportFailures() bool {
+	for _, result := range h.Result {
+		if !result.TestStatus && !result.TestSoft && (result.TestError == nil ||
+			result.TestError.Category != ErrorTransport) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// readyPollInterval is the interval between two requests of WaitForReady.
+const readyPollInterval = 100 * time.Millisecond
+
+// WaitForReady function requests the path until it responds with the expected status, and returns an error when it
+// does not within the timeout.
+//
+// Example usage:
+//
+// ```
+// if err := T.WaitForReady("/health", http.StatusOK, 10*time.Second); err != nil {
+// log.Fatal(err)
+// }
+// ```
+func (h *ApiTest) WaitForReady(path string, expectedStatus int, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var lastErr error
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, generateApiUrl(h.baseUrl(), path), nil)
+		if err != nil {
+			return err
 		}
 
-		os.Exit(0)
+		resp, respErr := h.client().Do(req)
+		if respErr == nil {
+			resp.Body.Close()
+
+			if resp.StatusCode == expectedStatus {
+				return nil
+			}
+
+			lastErr = fmt.Errorf("unexpected status code %s", resp.Status)
+		} else {
+			lastErr = respErr
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s not ready within %s: %w", path, timeout, lastErr)
+		case <-time.After(readyPollInterval):
+		}
+	}
+}
+
+// Close function closes the server and the idle connections of the client. It is safe to call Close more than once,
+// or on a nil ApiTest.
+//
+// Example usage:
+//
+// ```
+// T := InitApiTest()
+// defer T.Close()
+// ```
+func (h *ApiTest) Close() {
+	if h == nil {
+		return
+	}
+
+	if h.Server != nil {
+		h.Server.Close()
+	}
+
+	if h.Client != nil {
+		h.Client.CloseIdleConnections()
 	}
 }
@@ -0,0 +1,97 @@
+package gotest
+
+import (
+	"io"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ApiTestAggregateReport is the merged result of several ApiTest suites run by RunSuites.
+type ApiTestAggregateReport struct {
+	Suites  map[string]ApiTestSummary // Suites is the summary of every suite, by suite name.
+	Summary ApiTestSummary            // Summary is the summary of all the suites.
+	Results []ApiTestResult           // Results is the results of all the suites, renumbered, by suite name.
+}
+
+// RunSuites function runs the Requests of every suite concurrently, each with its own configuration, and merges
+// their results, ordered by suite name. The section of every merged result is prefixed by the name of its suite, like
+// "users / admin", so the shared reports keep them apart. The suites must not share their Server or Client state.
+//
+// Example usage:
+//
+// ```
+// report := RunSuites(map[string]*ApiTest{"users": usersTest, "orders": ordersTest})
+// report.Report(os.Stdout, ApiTestTableReporter{}, ReportTo(file, ApiTestJunitReporter{Name: "API"}))
+// ```
+func RunSuites(suites map[string]*ApiTest) ApiTestAggregateReport {
+	var group sync.WaitGroup
+	for _, suite := range suites {
+		group.Add(1)
+		go func(suite *ApiTest) {
+			defer group.Done()
+			suite.Run(suite.Requests)
+		}(suite)
+	}
+
+	group.Wait()
+
+	report := ApiTestAggregateReport{Suites: make(map[string]ApiTestSummary, len(suites))}
+
+	var times []time.Duration
+	for _, name := range sortedKeys(suites) {
+		summary := suites[name].Summary()
+		report.Suites[name] = summary
+
+		report.Summary.Tests += summary.Tests
+		report.Summary.PassedTests += summary.PassedTests
+		report.Summary.FailedTests += summary.FailedTests
+		report.Summary.SoftFailed += summary.SoftFailed
+		report.Summary.NotRunTests += summary.NotRunTests
+		report.Summary.Time += summary.Time
+		report.Summary.Duplicates += summary.Duplicates
+		report.Summary.IgnoredFailed += summary.IgnoredFailed
+		report.Summary.DuplicateRequests += summary.DuplicateRequests
+		report.Summary.Connections.New += summary.Connections.New
+		report.Summary.Connections.Reused += summary.Connections.Reused
+		report.Summary.Connections.Idle += summary.Connections.Idle
+
+		for _, result := range suites[name].Results() {
+			result.TestNumber = int64(len(report.Results) + 1)
+			result.TestSection = suiteSection(name, result.TestSection)
+
+			report.Results = append(report.Results, result)
+			times = append(times, result.TestTime)
+		}
+	}
+
+	slices.Sort(times)
+	report.Summary.P95, report.Summary.P99 = percentile(times, 95), percentile(times, 99)
+
+	return report
+}
+
+// suiteSection function returns the section of a result prefixed by the name of its suite.
+func suiteSection(suite string, section string) string {
+	if section == "" {
+		return suite
+	}
+
+	return suite + " / " + section
+}
+
+// Report function writes the merged result of the suites to w with every reporter, in order, like Report.
+func (r ApiTestAggregateReport) Report(w io.Writer, reporters ...ApiTestReporter) error {
+	for _, reporter := range reporters {
+		if err := reporter.Report(w, r.Summary, r.Results); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Failed function reports whether a test case of the suites failed, apart from the Soft test cases.
+func (r ApiTestAggregateReport) Failed() bool {
+	return r.Summary.FailedTests > r.Summary.SoftFailed
+}
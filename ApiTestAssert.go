@@ -0,0 +1,1043 @@
+package gotest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ApiTestAssertion is an assertion expression evaluated against the response of a test case.
+//
+// Example usage:
+//
+// ```
+// // Status is 200 or 202, and the body contains "id", and the Location header is present
+// assertion := AssertAnd(AssertOr(AssertStatus(200), AssertStatus(202)), AssertBodyContains("id"),
+// AssertHeader("Location", ""))
+// T.CreateTest(ApiTestRequest{ApiUrl: "/items", ApiMethod: http.MethodPost, Assertion: assertion})
+// ```
+type ApiTestAssertion interface {
+	// Evaluate reports whether the assertion holds for the response, along with a breakdown of its conditions.
+	Evaluate(resp *ApiTestResponseCapture) (bool, string)
+}
+
+// assertionFunc is a function implementing ApiTestAssertion.
+type assertionFunc func(resp *ApiTestResponseCapture) (bool, string)
+
+// Evaluate function calls the assertion function.
+func (f assertionFunc) Evaluate(resp *ApiTestResponseCapture) (bool, string) {
+	return f(resp)
+}
+
+// AssertStatus function returns an assertion that holds when the response status code is one of the codes.
+func AssertStatus(codes ...int) ApiTestAssertion {
+	return assertionFunc(func(resp *ApiTestResponseCapture) (bool, string) {
+		for _, code := range codes {
+			if resp.Status == code {
+				return true, fmt.Sprintf("status %d in %v: true", resp.Status, codes)
+			}
+		}
+
+		return false, fmt.Sprintf("status %d in %v: false", resp.Status, codes)
+	})
+}
+
+// AssertBodyContains function returns an assertion that holds when the response body contains the text.
+func AssertBodyContains(text string) ApiTestAssertion {
+	return assertionFunc(func(resp *ApiTestResponseCapture) (bool, string) {
+		held := bytes.Contains(resp.Body, []byte(text))
+		return held, fmt.Sprintf("body contains %q: %t", text, held)
+	})
+}
+
+// AssertHeader function returns an assertion that holds when the response has the header, with the value when it
+// is not empty.
+func AssertHeader(name string, value string) ApiTestAssertion {
+	return assertionFunc(func(resp *ApiTestResponseCapture) (bool, string) {
+		values, isPresent := resp.Header[http.CanonicalHeaderKey(name)]
+		if value == "" {
+			return isPresent, fmt.Sprintf("header %s present: %t", name, isPresent)
+		}
+
+		held := isPresent && values[0] == value
+		return held, fmt.Sprintf("header %s == %q: %t", name, value, held)
+	})
+}
+
+// AssertAnd function returns an assertion that holds when all the assertions hold.
+func AssertAnd(assertions ...ApiTestAssertion) ApiTestAssertion {
+	return assertionFunc(func(resp *ApiTestResponseCapture) (bool, string) {
+		return evaluateGroup("and", assertions, resp, true)
+	})
+}
+
+// AssertOr function returns an assertion that holds when at least one of the assertions holds.
+func AssertOr(assertions ...ApiTestAssertion) ApiTestAssertion {
+	return assertionFunc(func(resp *ApiTestResponseCapture) (bool, string) {
+		return evaluateGroup("or", assertions, resp, false)
+	})
+}
+
+// RequireJsonErrorEnvelope function returns an assertion that holds for the 2xx responses, and for the other
+// responses when their body is JSON with all the fields, given as JSON paths, like "error.code". Set in the
+// DefaultAssertions of the ApiTest, it enforces the same error contract on every endpoint.
+//
+// Example usage:
+//
+// ```
+// T.DefaultAssertions = []ApiTestAssertion{RequireJsonErrorEnvelope("error", "message", "traceId")}
+// ```
+func RequireJsonErrorEnvelope(fields ...string) ApiTestAssertion {
+	return assertionFunc(func(resp *ApiTestResponseCapture) (bool, string) {
+		if resp.Status >= 200 && resp.Status < 300 {
+			return true, fmt.Sprintf("error envelope of status %d: not required", resp.Status)
+		}
+
+		body, err := unmarshalJson(resp.Body)
+		if err != nil {
+			return false, fmt.Sprintf("error envelope of status %d: body is not valid JSON", resp.Status)
+		}
+
+		for _, field := range fields {
+			if _, err := lookupJsonPath(body, field); err != nil {
+				return false, fmt.Sprintf("error envelope of status %d: missing field %q", resp.Status, field)
+			}
+		}
+
+		return true, fmt.Sprintf("error envelope of status %d: true", resp.Status)
+	})
+}
+
+// evaluateGroup function evaluates every assertion of a group and combines them with AND when all is true and with
+// OR otherwise.
+func evaluateGroup(name string, assertions []ApiTestAssertion, resp *ApiTestResponseCapture, all bool) (bool, string) {
+	held := all
+	parts := make([]string, 0, len(assertions))
+
+	for _, assertion := range assertions {
+		isHeld, breakdown := assertion.Evaluate(resp)
+		parts = append(parts, breakdown)
+
+		if all {
+			held = held && isHeld
+		} else {
+			held = held || isHeld
+		}
+	}
+
+	return held, name + "(" + strings.Join(parts, ", ") + ")"
+}
+
+// checkAssertion function evaluates the DefaultAssertions of the ApiTest, then the Assertion of the test case.
+func checkAssertion(h *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	assertions := h.DefaultAssertions
+	if httpReq.Assertion != nil {
+		assertions = append(slices.Clip(assertions), httpReq.Assertion)
+	}
+
+	for _, assertion := range assertions {
+		if held, breakdown := assertion.Evaluate(resp); !held {
+			return errors.New("assertion failed: " + breakdown)
+		}
+	}
+
+	return nil
+}
+
+// responseCheck is a check of the response of a test case, it returns a non-nil error when the check fails.
+type responseCheck func(h *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error
+
+// responseChecks is the list of checks run in order against the response of a test case with the expected status.
+var responseChecks = []responseCheck{
+	checkServerError,
+	checkExpectedProto,
+	checkExpectedTls,
+	checkValidJson,
+	checkExpectedBody,
+	checkExpectedBodyFile,
+	checkExpectedBodySha256,
+	checkExpectedBodyOneOf,
+	checkAssertion,
+	checkExpectedJsonFields,
+	checkExpectedBodySubset,
+	checkRequireNonNull,
+	checkExpectedJsonPath,
+	checkExpectedJsonFieldsApprox,
+	checkExpectedShape,
+	checkExpectedXsd,
+	checkExpectedSchemaOneOf,
+	checkExpectedOrder,
+	checkExpectedErrorField,
+	checkExpectedTrailers,
+	checkExpectedHeaders,
+	checkExpectedHeaderPatterns,
+	checkForbiddenHeaders,
+	checkContentLength,
+	checkChunkTiming,
+	checkExpectedCharset,
+	checkExpectedCacheControl,
+	checkExpectedCache,
+	checkValidUtf8,
+	checkExpectedCookieAttrs,
+	checkExpectedRedirect,
+	checkExpectedRedirectChain,
+	checkExpectedAuthChallenge,
+	checkRateLimitHeaders,
+	checkBindResponse,
+}
+
+// checkExpectedBody function compares the response body with the ExpectedBody of the test case.
+func checkExpectedBody(h *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if httpReq.ExpectedBody == nil {
+		return nil
+	}
+
+	return h.bodyComparer(httpReq, resp)(httpReq.ExpectedBody, resp.Body)
+}
+
+// checkExpectedBodyFile function compares the response body with the contents of the ExpectedBodyFile of the test
+// case.
+func checkExpectedBodyFile(h *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if httpReq.ExpectedBodyFile == "" {
+		return nil
+	}
+
+	expected, err := os.ReadFile(httpReq.ExpectedBodyFile)
+	if err != nil {
+		return fmt.Errorf("expected body file: %w", err)
+	}
+
+	return h.textComparer(httpReq)(expected, resp.Body)
+}
+
+// checkExpectedBodySha256 function checks that the SHA-256 of the response body is the ExpectedBodySha256 of the test
+// case.
+func checkExpectedBodySha256(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if httpReq.ExpectedBodySha256 == "" {
+		return nil
+	}
+
+	sum := sha256.Sum256(resp.Body)
+	if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, httpReq.ExpectedBodySha256) {
+		return fmt.Errorf("response body SHA-256 mismatch: expected %s, got %s (%d bytes)",
+			strings.ToLower(httpReq.ExpectedBodySha256), actual, len(resp.Body))
+	}
+
+	return nil
+}
+
+// FileSha256 function returns the hexadecimal SHA-256 of a file, streamed without reading it whole, like for the
+// ExpectedBodySha256 of the echo of an uploaded file.
+//
+// Example usage:
+//
+// ```
+// sum, err := FileSha256("testdata/image.png")
+// ```
+func FileSha256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// checkExpectedBodyOneOf function checks that the response body matches at least one of the ExpectedBodyOneOf
+// candidates of the test case.
+func checkExpectedBodyOneOf(h *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if len(httpReq.ExpectedBodyOneOf) == 0 {
+		return nil
+	}
+
+	compare := h.bodyComparer(httpReq, resp)
+
+	mismatches := make([]string, 0, len(httpReq.ExpectedBodyOneOf))
+	for i, expected := range httpReq.ExpectedBodyOneOf {
+		err := compare(expected, resp.Body)
+		if err == nil {
+			return nil
+		}
+
+		mismatches = append(mismatches, fmt.Sprintf("candidate %d: %s", i+1, err))
+	}
+
+	return fmt.Errorf("response body matches none of the %d expected bodies\n%s", len(mismatches),
+		strings.Join(mismatches, "\n"))
+}
+
+// checkServerError function fails any 5xx response when FailOnServerError is set, unless the ExpectedStatus of the
+// test case is a 5xx status code, or its StatusMatcher or ExpectedStatusRange accepted it.
+func checkServerError(h *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if !h.FailOnServerError || resp.Status < 500 || resp.Status > 599 {
+		return nil
+	}
+
+	if expected, isInt := httpReq.ExpectedStatus.(int); isInt && expected >= 500 && expected <= 599 {
+		return nil
+	}
+
+	if httpReq.StatusMatcher != nil || httpReq.ExpectedStatusRange != [2]int{} {
+		return nil
+	}
+
+	return fmt.Errorf("server error %d %s from %s %s", resp.Status, http.StatusText(resp.Status), httpReq.ApiMethod,
+		resp.Url)
+}
+
+// checkExpectedProto function checks that the protocol of the response is the ExpectedProto of the test case.
+func checkExpectedProto(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if httpReq.ExpectedProto == "" || resp.Proto == httpReq.ExpectedProto {
+		return nil
+	}
+
+	return fmt.Errorf("protocol: expected %s, got %s", httpReq.ExpectedProto, resp.Proto)
+}
+
+// ApiTestBodyComparer compares an expected body with the body of a response, and returns an error describing their
+// mismatch, like by decoding the body into a message of the type of the expected body.
+type ApiTestBodyComparer func(expected interface{}, actual []byte) error
+
+// mediaType function returns the media type of a content type, without its parameters.
+func mediaType(contentType string) string {
+	media, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	return media
+}
+
+// bodyComparer function returns the comparer of the expected bodies of a response, the one of the BodyComparers for
+// its media type or else the text comparer of the test case.
+func (h *ApiTest) bodyComparer(httpReq ApiTestRequest, resp *ApiTestResponseCapture) ApiTestBodyComparer {
+	if comparer, isPresent := h.BodyComparers[mediaType(resp.Header.Get("Content-Type"))]; isPresent {
+		return comparer
+	}
+
+	return h.textComparer(httpReq)
+}
+
+// textComparer function returns the comparer of the JSON and text bodies of a test case, compareBody or
+// compareNormalizedBody when NormalizeWhitespace is set.
+func (h *ApiTest) textComparer(httpReq ApiTestRequest) ApiTestBodyComparer {
+	if httpReq.NormalizeWhitespace {
+		return h.compareNormalizedBody
+	}
+
+	return h.compareBody
+}
+
+// compareNormalizedBody function compares a response body with an expected body like compareBody, except that the
+// text bodies are compared after collapsing their runs of whitespace into a space and trimming them.
+func (h *ApiTest) compareNormalizedBody(expected interface{}, actual []byte) error {
+	var expectedBytes []byte
+
+	switch value := expected.(type) {
+	case string:
+		expectedBytes = []byte(value)
+	case []byte:
+		expectedBytes = value
+	default:
+		return h.compareBody(expected, actual)
+	}
+
+	if json.Valid(expectedBytes) && json.Valid(actual) {
+		return h.compareBody(expected, actual)
+	}
+
+	normalizedExpected := strings.Join(strings.Fields(string(expectedBytes)), " ")
+	normalizedActual := strings.Join(strings.Fields(string(actual)), " ")
+	if normalizedExpected != normalizedActual {
+		return h.bodyMismatch(normalizedExpected, normalizedActual)
+	}
+
+	return nil
+}
+
+// compareBody function compares a response body with an expected body. A string or []byte expected body is compared
+// as JSON when both sides are valid JSON and as text otherwise, any other value is marshaled and compared as JSON. JSON
+// numbers are compared by value with full precision, not as float64, so large integers like 64-bit IDs do not match
+// their neighbors.
+func (h *ApiTest) compareBody(expected interface{}, actual []byte) error {
+	var expectedBytes []byte
+	isRaw := true
+
+	switch value := expected.(type) {
+	case string:
+		expectedBytes = []byte(value)
+	case []byte:
+		expectedBytes = value
+	default:
+		jsonBytes, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		expectedBytes = jsonBytes
+		isRaw = false
+	}
+
+	if isRaw && (!json.Valid(expectedBytes) || !json.Valid(actual)) {
+		if !bytes.Equal(expectedBytes, actual) {
+			return h.bodyMismatch(string(expectedBytes), string(actual))
+		}
+
+		return nil
+	}
+
+	expectedJson, err := unmarshalJson(expectedBytes)
+	if err != nil {
+		return err
+	}
+
+	actualJson, err := decodeJson(actual)
+	if err != nil {
+		return err
+	}
+
+	if !jsonEqual(expectedJson, actualJson) {
+		return h.bodyMismatch(indentJson(expectedJson), indentJson(actualJson))
+	}
+
+	return nil
+}
+
+// bodyMismatch function returns the error of a body mismatch, with a line-by-line diff when Verbose is set.
+func (h *ApiTest) bodyMismatch(expected string, actual string) error {
+	message := "response body does not match\nexpected:\n" + expected + "\nactual:\n" + actual
+
+	if h.Verbose {
+		message += "\ndiff:\n" + strings.Join(diffLines(strings.Split(expected, "\n"), strings.Split(actual, "\n")), "\n")
+	}
+
+	return errors.New(message)
+}
+
+// indentJson function returns the indented JSON encoding of a decoded JSON value.
+func indentJson(value interface{}) string {
+	jsonBytes, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonBytes)
+}
+
+// diffLines function returns a line-by-line diff of two texts, prefixing removed lines with "- ", added lines with
+// "+ " and common lines with "  ".
+func diffLines(expected []string, actual []string) []string {
+	common := make([][]int, len(expected)+1)
+	for i := range common {
+		common[i] = make([]int, len(actual)+1)
+	}
+
+	for i := len(expected) - 1; i >= 0; i-- {
+		for j := len(actual) - 1; j >= 0; j-- {
+			if expected[i] == actual[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else if common[i+1][j] >= common[i][j+1] {
+				common[i][j] = common[i+1][j]
+			} else {
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(expected) && j < len(actual) {
+		switch {
+		case expected[i] == actual[j]:
+			lines = append(lines, "  "+expected[i])
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, "- "+expected[i])
+			i++
+		default:
+			lines = append(lines, "+ "+actual[j])
+			j++
+		}
+	}
+
+	for ; i < len(expected); i++ {
+		lines = append(lines, "- "+expected[i])
+	}
+
+	for ; j < len(actual); j++ {
+		lines = append(lines, "+ "+actual[j])
+	}
+
+	return lines
+}
+
+// ApiTestApprox is an expected numeric value with a tolerance.
+type ApiTestApprox struct {
+	Value   float64 // Value is the expected value.
+	Epsilon float64 // Epsilon is the maximum allowed absolute difference from Value.
+}
+
+// checkExpectedJsonFields function compares the JSON fields at the paths of ExpectedJsonFields.
+func checkExpectedJsonFields(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if len(httpReq.ExpectedJsonFields) == 0 {
+		return nil
+	}
+
+	body, err := decodeJson(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range sortedKeys(httpReq.ExpectedJsonFields) {
+		actual, err := lookupJsonPath(body, path)
+		if err != nil {
+			return err
+		}
+
+		expected, err := normalizeJson(httpReq.ExpectedJsonFields[path])
+		if err != nil {
+			return err
+		}
+
+		if !jsonEqual(expected, actual) {
+			return fmt.Errorf("JSON field %q: expected %v, got %v", path, expected, actual)
+		}
+	}
+
+	return nil
+}
+
+// checkExpectedBodySubset function checks that the JSON body of the response contains the ExpectedBodySubset of the
+// test case, ignoring the extra keys of its objects.
+func checkExpectedBodySubset(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if httpReq.ExpectedBodySubset == nil {
+		return nil
+	}
+
+	body, err := decodeJson(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	expected, err := normalizeJson(httpReq.ExpectedBodySubset)
+	if err != nil {
+		return err
+	}
+
+	if err := jsonSubset(expected, body, ""); err != nil {
+		return errors.New("response body does not contain the expected subset: " + err.Error())
+	}
+
+	return nil
+}
+
+// checkExpectedJsonPath function evaluates the JSONPath expressions of ExpectedJsonPath against the JSON body of the
+// response with the JsonPathEvaluator of the ApiTest, and compares their results with the expected values.
+func checkExpectedJsonPath(h *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if len(httpReq.ExpectedJsonPath) == 0 {
+		return nil
+	}
+
+	if h.JsonPathEvaluator == nil {
+		return errors.New("ExpectedJsonPath requires a JsonPathEvaluator")
+	}
+
+	for _, path := range sortedKeys(httpReq.ExpectedJsonPath) {
+		result, err := h.JsonPathEvaluator(path, resp.Body)
+		if err != nil {
+			return fmt.Errorf("JSONPath %q: %w", path, err)
+		}
+
+		actual, err := normalizeJson(result)
+		if err != nil {
+			return fmt.Errorf("JSONPath %q: %w", path, err)
+		}
+
+		expected, err := normalizeJson(httpReq.ExpectedJsonPath[path])
+		if err != nil {
+			return err
+		}
+
+		if !jsonEqual(expected, actual) {
+			return fmt.Errorf("JSONPath %q: expected %v, got %v", path, expected, actual)
+		}
+	}
+
+	return nil
+}
+
+// checkRequireNonNull function checks that the paths of RequireNonNull are present and not null in the JSON body of
+// the response, and not empty with RequireNonEmpty. The error lists every failed path.
+func checkRequireNonNull(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if len(httpReq.RequireNonNull) == 0 {
+		return nil
+	}
+
+	body, err := decodeJson(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var failures []string
+	for _, path := range httpReq.RequireNonNull {
+		value, err := lookupJsonPath(body, path)
+		if err != nil {
+			failures = append(failures, path+" missing")
+			continue
+		}
+
+		if value == nil {
+			failures = append(failures, path+" null")
+			continue
+		}
+
+		if httpReq.RequireNonEmpty && isEmptyJson(value) {
+			failures = append(failures, path+" empty")
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.New("required JSON fields: " + strings.Join(failures, ", "))
+	}
+
+	return nil
+}
+
+// isEmptyJson function reports whether a decoded JSON value is an empty string, array or object.
+func isEmptyJson(value interface{}) bool {
+	switch node := value.(type) {
+	case string:
+		return node == ""
+	case []interface{}:
+		return len(node) == 0
+	case map[string]interface{}:
+		return len(node) == 0
+	default:
+		return false
+	}
+}
+
+// checkExpectedJsonFieldsApprox function compares the numeric JSON fields at the paths of ExpectedJsonFieldsApprox
+// within their tolerance.
+func checkExpectedJsonFieldsApprox(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if len(httpReq.ExpectedJsonFieldsApprox) == 0 {
+		return nil
+	}
+
+	body, err := decodeJson(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range sortedKeys(httpReq.ExpectedJsonFieldsApprox) {
+		expected := httpReq.ExpectedJsonFieldsApprox[path]
+
+		actual, err := lookupJsonPath(body, path)
+		if err != nil {
+			return err
+		}
+
+		jsonNumber, isNumber := actual.(json.Number)
+		if !isNumber {
+			return fmt.Errorf("JSON field %q: expected a number, got %v", path, actual)
+		}
+
+		number, err := jsonNumber.Float64()
+		if err != nil {
+			return fmt.Errorf("JSON field %q: %w", path, err)
+		}
+
+		if math.Abs(number-expected.Value) > expected.Epsilon {
+			return fmt.Errorf("JSON field %q: expected %v ± %v, got %v", path, expected.Value, expected.Epsilon, number)
+		}
+	}
+
+	return nil
+}
+
+// sortedKeys function returns the keys of a map in ascending order.
+func sortedKeys[V interface{}](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// checkExpectedShape function decodes the response body into a new value of the type of ExpectedShape and validates
+// it with the Validator of the ApiTest, when set.
+func checkExpectedShape(h *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if httpReq.ExpectedShape == nil {
+		return nil
+	}
+
+	shapeType := reflect.TypeOf(httpReq.ExpectedShape)
+	if shapeType.Kind() == reflect.Pointer {
+		shapeType = shapeType.Elem()
+	}
+
+	value := reflect.New(shapeType).Interface()
+	if err := json.Unmarshal(resp.Body, value); err != nil {
+		return fmt.Errorf("response body does not match %s: %w", shapeType, err)
+	}
+
+	if h.Validator != nil {
+		if err := h.Validator(value); err != nil {
+			return fmt.Errorf("response body does not validate as %s: %w", shapeType, err)
+		}
+	}
+
+	return nil
+}
+
+// checkExpectedTrailers function compares the response trailers with the ExpectedTrailers of the test case.
+func checkExpectedTrailers(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	for _, name := range sortedKeys(httpReq.ExpectedTrailers) {
+		if actual := resp.Trailer.Get(name); actual != httpReq.ExpectedTrailers[name] {
+			return fmt.Errorf("trailer %s: expected %q, got %q", name, httpReq.ExpectedTrailers[name], actual)
+		}
+	}
+
+	return nil
+}
+
+// checkExpectedHeaders function compares the response headers with the ExpectedHeaders of the test case.
+func checkExpectedHeaders(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	for _, name := range sortedKeys(httpReq.ExpectedHeaders) {
+		values, isPresent := resp.Header[http.CanonicalHeaderKey(name)]
+		if !isPresent {
+			return fmt.Errorf("header %s not present", name)
+		}
+
+		if values[0] != httpReq.ExpectedHeaders[name] {
+			return fmt.Errorf("header %s: expected %q, got %q", name, httpReq.ExpectedHeaders[name], values[0])
+		}
+	}
+
+	return nil
+}
+
+// checkExpectedHeaderPatterns function matches the response headers with the regular expressions of the
+// ExpectedHeaderPatterns of the test case.
+func checkExpectedHeaderPatterns(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	for _, name := range sortedKeys(httpReq.ExpectedHeaderPatterns) {
+		pattern := httpReq.ExpectedHeaderPatterns[name]
+
+		expression, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("header %s: invalid pattern %q: %w", name, pattern, err)
+		}
+
+		values, isPresent := resp.Header[http.CanonicalHeaderKey(name)]
+		if !isPresent {
+			return fmt.Errorf("header %s not present", name)
+		}
+
+		if !expression.MatchString(values[0]) {
+			return fmt.Errorf("header %s: %q does not match pattern %q", name, values[0], pattern)
+		}
+	}
+
+	return nil
+}
+
+// ApiTestOrder is the expected order of a JSON array of the response body.
+type ApiTestOrder struct {
+	Path       string // Path is the JSON path of the array.
+	Field      string // Field is the JSON path, within the elements, of the compared value, empty for the elements.
+	Descending bool   // Descending is whether the array is sorted in descending order instead of ascending.
+}
+
+// checkExpectedOrder function checks that the JSON array of the ExpectedOrder of the test case is sorted.
+func checkExpectedOrder(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	order := httpReq.ExpectedOrder
+	if order == nil {
+		return nil
+	}
+
+	body, err := decodeJson(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	value, err := lookupJsonPath(body, order.Path)
+	if err != nil {
+		return err
+	}
+
+	elements, isArray := value.([]interface{})
+	if !isArray {
+		return fmt.Errorf("JSON field %q is not an array", order.Path)
+	}
+
+	direction := "ascending"
+	if order.Descending {
+		direction = "descending"
+	}
+
+	var previous interface{}
+	for i, element := range elements {
+		current, err := lookupJsonPath(element, order.Field)
+		if err != nil {
+			return fmt.Errorf("element %d of %q: %w", i, order.Path, err)
+		}
+
+		if i > 0 {
+			comparison, err := compareJsonValues(previous, current)
+			if err != nil {
+				return fmt.Errorf("elements %d and %d of %q: %w", i-1, i, order.Path, err)
+			}
+
+			if (!order.Descending && comparison > 0) || (order.Descending && comparison < 0) {
+				return fmt.Errorf("array %q is not sorted %s by %q: element %d (%v) before element %d (%v)",
+					order.Path, direction, order.Field, i-1, previous, i, current)
+			}
+		}
+
+		previous = current
+	}
+
+	return nil
+}
+
+// ApiTestErrorField is an expected field of the structured error of an error response.
+type ApiTestErrorField struct {
+	Path  string      // Path is the JSON path of the field, like "error.code".
+	Value interface{} // Value is the expected value of the field.
+}
+
+// checkExpectedErrorField function checks that the response is an error response whose body has the
+// ExpectedErrorField of the test case.
+func checkExpectedErrorField(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	field := httpReq.ExpectedErrorField
+	if field.Path == "" {
+		return nil
+	}
+
+	if resp.Status >= 200 && resp.Status < 300 {
+		return fmt.Errorf("error field %q: expected an error response, got status %d", field.Path, resp.Status)
+	}
+
+	body, err := decodeJson(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	actual, err := lookupJsonPath(body, field.Path)
+	if err != nil {
+		return fmt.Errorf("error field: %w", err)
+	}
+
+	expected, err := normalizeJson(field.Value)
+	if err != nil {
+		return err
+	}
+
+	if !jsonEqual(expected, actual) {
+		return fmt.Errorf("error field %q: expected %v, got %v", field.Path, expected, actual)
+	}
+
+	return nil
+}
+
+// checkForbiddenHeaders function checks that none of the ForbiddenHeaders of the test case is in the response. The
+// value of a found header is redacted like in the captures.
+func checkForbiddenHeaders(h *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	for _, name := range httpReq.ForbiddenHeaders {
+		name = http.CanonicalHeaderKey(name)
+
+		values, isPresent := resp.Header[name]
+		if isPresent {
+			redacted := h.redactHeader(http.Header{name: values})
+			return fmt.Errorf("header %s is forbidden, got %q", name, strings.Join(redacted[name], ", "))
+		}
+	}
+
+	return nil
+}
+
+// checkContentLength function checks that the Content-Length header of the response is the length of its body when
+// AssertContentLength is set.
+func checkContentLength(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if !httpReq.AssertContentLength {
+		return nil
+	}
+
+	value := resp.Header.Get("Content-Length")
+	if value == "" {
+		return errors.New("header Content-Length not present")
+	}
+
+	declared, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("header Content-Length: invalid value %q", value)
+	}
+
+	if httpReq.ApiMethod == http.MethodHead {
+		return nil
+	}
+
+	if declared != int64(len(resp.Body)) {
+		return fmt.Errorf("header Content-Length: declared %d bytes, read %d bytes", declared, len(resp.Body))
+	}
+
+	return nil
+}
+
+// checkExpectedCharset function checks that the charset declared by the Content-Type header of the response is the
+// ExpectedCharset of the test case, case-insensitively.
+func checkExpectedCharset(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if httpReq.ExpectedCharset == "" {
+		return nil
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("header Content-Type: invalid value %q", contentType)
+	}
+
+	charset, isPresent := params["charset"]
+	if !isPresent {
+		return fmt.Errorf("charset: expected %s, none declared in %q", httpReq.ExpectedCharset, contentType)
+	}
+
+	if !strings.EqualFold(charset, httpReq.ExpectedCharset) {
+		return fmt.Errorf("charset: expected %s, declared %s", httpReq.ExpectedCharset, charset)
+	}
+
+	return nil
+}
+
+// checkValidUtf8 function checks that the response body is valid UTF-8 when AssertValidUtf8 is set.
+func checkValidUtf8(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if !httpReq.AssertValidUtf8 {
+		return nil
+	}
+
+	for offset := 0; offset < len(resp.Body); {
+		char, size := utf8.DecodeRune(resp.Body[offset:])
+		if char == utf8.RuneError && size <= 1 {
+			return fmt.Errorf("response body is not valid UTF-8 at byte %d", offset)
+		}
+
+		offset += size
+	}
+
+	return nil
+}
+
+// sameSiteNames is the names of the SameSite modes of cookies.
+var sameSiteNames = map[http.SameSite]string{
+	http.SameSiteDefaultMode: "Default",
+	http.SameSiteLaxMode:     "Lax",
+	http.SameSiteStrictMode:  "Strict",
+	http.SameSiteNoneMode:    "None",
+}
+
+// checkExpectedCookieAttrs function checks that the cookies set by the response have at least the attributes of the
+// ExpectedCookieAttrs of the test case. Only the attributes set in the expected cookies are compared.
+func checkExpectedCookieAttrs(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if len(httpReq.ExpectedCookieAttrs) == 0 {
+		return nil
+	}
+
+	cookies := make(map[string]*http.Cookie)
+	for _, cookie := range (&http.Response{Header: resp.Header}).Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+
+	for _, name := range sortedKeys(httpReq.ExpectedCookieAttrs) {
+		expected := httpReq.ExpectedCookieAttrs[name]
+
+		actual, isPresent := cookies[name]
+		if !isPresent {
+			return fmt.Errorf("cookie %s not set", name)
+		}
+
+		switch {
+		case expected.Value != "" && actual.Value != expected.Value:
+			return fmt.Errorf("cookie %s: expected value %q, got %q", name, expected.Value, actual.Value)
+		case expected.Path != "" && actual.Path != expected.Path:
+			return fmt.Errorf("cookie %s: expected Path %q, got %q", name, expected.Path, actual.Path)
+		case expected.Domain != "" && actual.Domain != expected.Domain:
+			return fmt.Errorf("cookie %s: expected Domain %q, got %q", name, expected.Domain, actual.Domain)
+		case expected.Secure && !actual.Secure:
+			return fmt.Errorf("cookie %s: expected Secure, not set", name)
+		case expected.HttpOnly && !actual.HttpOnly:
+			return fmt.Errorf("cookie %s: expected HttpOnly, not set", name)
+		case expected.SameSite != 0 && actual.SameSite != expected.SameSite:
+			return fmt.Errorf("cookie %s: expected SameSite=%s, got SameSite=%s", name,
+				sameSiteNames[expected.SameSite], sameSiteNames[actual.SameSite])
+		}
+	}
+
+	return nil
+}
+
+// checkHeadRequest function checks that a HEAD test case has no body expectation, since its response has no body.
+func checkHeadRequest(httpReq ApiTestRequest) error {
+	if httpReq.ApiMethod != http.MethodHead {
+		return nil
+	}
+
+	expectations := []struct {
+		name  string
+		isSet bool
+	}{
+		{"ExpectedBody", httpReq.ExpectedBody != nil},
+		{"ExpectedBodyFile", httpReq.ExpectedBodyFile != ""},
+		{"ExpectedBodySha256", httpReq.ExpectedBodySha256 != ""},
+		{"ExpectedBodyFunc", httpReq.ExpectedBodyFunc != nil},
+		{"ExpectedBodyOneOf", len(httpReq.ExpectedBodyOneOf) > 0},
+		{"ExpectValidJson", httpReq.ExpectValidJson},
+		{"ExpectedJsonFields", len(httpReq.ExpectedJsonFields) > 0},
+		{"ExpectedBodySubset", httpReq.ExpectedBodySubset != nil},
+		{"RequireNonNull", len(httpReq.RequireNonNull) > 0},
+		{"ExpectedJsonPath", len(httpReq.ExpectedJsonPath) > 0},
+		{"ExpectedJsonFieldsApprox", len(httpReq.ExpectedJsonFieldsApprox) > 0},
+		{"ExpectedShape", httpReq.ExpectedShape != nil},
+		{"ExpectedXsd", httpReq.ExpectedXsd != ""},
+		{"ExpectedSchemaOneOf", len(httpReq.ExpectedSchemaOneOf) > 0},
+		{"ExpectedOrder", httpReq.ExpectedOrder != nil},
+		{"ExpectedErrorField", httpReq.ExpectedErrorField.Path != ""},
+		{"AssertValidUtf8", httpReq.AssertValidUtf8},
+		{"MaxFirstByteTime", httpReq.MaxFirstByteTime > 0},
+		{"MaxChunkInterval", httpReq.MaxChunkInterval > 0},
+		{"CaptureJson", len(httpReq.CaptureJson) > 0},
+		{"BindResponse", httpReq.BindResponse != nil},
+	}
+
+	for _, expectation := range expectations {
+		if expectation.isSet {
+			return fmt.Errorf("HEAD responses have no body: %s cannot be checked", expectation.name)
+		}
+	}
+
+	return nil
+}
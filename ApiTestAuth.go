@@ -0,0 +1,174 @@
+package gotest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// authChallenge is a challenge of a WWW-Authenticate header, like `Bearer realm="api", error="invalid_token"`.
+type authChallenge struct {
+	scheme string            // scheme is the authentication scheme, like Bearer.
+	params map[string]string // params is the parameters of the challenge, by lowercase name.
+}
+
+// parseAuthChallenges function parses the challenges of WWW-Authenticate header values, following RFC 9110. The
+// token68 of a challenge, like of Negotiate, is kept as its parameter of empty name.
+func parseAuthChallenges(values []string) ([]authChallenge, error) {
+	var challenges []authChallenge
+
+	for _, value := range values {
+		rest := value
+		for {
+			rest = strings.TrimLeft(rest, " \t,")
+			if rest == "" {
+				break
+			}
+
+			var scheme string
+			scheme, rest = readAuthToken(rest)
+			if scheme == "" {
+				return nil, fmt.Errorf("invalid challenge %q", value)
+			}
+
+			challenge := authChallenge{scheme: scheme, params: make(map[string]string)}
+			for {
+				rest = strings.TrimLeft(rest, " \t")
+				if strings.HasPrefix(rest, ",") {
+					if !isAuthParam(strings.TrimLeft(rest, " \t,")) {
+						break
+					}
+
+					rest = strings.TrimLeft(rest, " \t,")
+				}
+
+				if rest == "" {
+					break
+				}
+
+				if !isAuthParam(rest) {
+					token68, remaining, _ := strings.Cut(rest, ",")
+					challenge.params[""] = strings.TrimSpace(token68)
+					rest = strings.TrimPrefix(rest, token68)
+					if remaining == "" {
+						rest = ""
+					}
+
+					continue
+				}
+
+				name, after := readAuthToken(rest)
+				after = strings.TrimLeft(after, " \t")
+
+				paramValue, remaining, err := readAuthValue(strings.TrimLeft(after[1:], " \t"))
+				if err != nil {
+					return nil, fmt.Errorf("invalid challenge %q: %w", value, err)
+				}
+
+				challenge.params[strings.ToLower(name)] = paramValue
+				rest = remaining
+			}
+
+			challenges = append(challenges, challenge)
+		}
+	}
+
+	return challenges, nil
+}
+
+// isAuthParam function reports whether a text starts with an auth parameter, like `realm="api"`, rather than with
+// the scheme of another challenge.
+func isAuthParam(text string) bool {
+	name, after := readAuthToken(text)
+	after = strings.TrimLeft(after, " \t")
+	if name == "" || !strings.HasPrefix(after, "=") {
+		return false
+	}
+
+	value := strings.TrimLeft(after[1:], " \t")
+	return value != "" && value[0] != '=' && value[0] != ','
+}
+
+// readAuthToken function reads a token at the start of a text, and returns it with the rest of the text.
+func readAuthToken(text string) (string, string) {
+	end := strings.IndexAny(text, " \t,=\"")
+	if end < 0 {
+		end = len(text)
+	}
+
+	return text[:end], text[end:]
+}
+
+// readAuthValue function reads a token or a quoted string at the start of a text, and returns it unquoted with the
+// rest of the text.
+func readAuthValue(text string) (string, string, error) {
+	if !strings.HasPrefix(text, `"`) {
+		value, rest := readAuthToken(text)
+		return value, rest, nil
+	}
+
+	var builder strings.Builder
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			if i+1 < len(text) {
+				i++
+				builder.WriteByte(text[i])
+			}
+		case '"':
+			return builder.String(), text[i+1:], nil
+		default:
+			builder.WriteByte(text[i])
+		}
+	}
+
+	return "", "", errors.New("unterminated quoted string")
+}
+
+// checkExpectedAuthChallenge function checks that the response is a 401 response whose WWW-Authenticate header has a
+// challenge of the scheme of the ExpectedAuthChallenge of the test case, with all its parameters. The schemes and
+// parameter names are compared case-insensitively, and the parameter values exactly.
+func checkExpectedAuthChallenge(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if httpReq.ExpectedAuthChallenge == "" {
+		return nil
+	}
+
+	if resp.Status != http.StatusUnauthorized {
+		return fmt.Errorf("authentication challenge: expected a 401 response, got %d", resp.Status)
+	}
+
+	expected, err := parseAuthChallenges([]string{httpReq.ExpectedAuthChallenge})
+	if err != nil || len(expected) != 1 {
+		return fmt.Errorf("invalid ExpectedAuthChallenge %q", httpReq.ExpectedAuthChallenge)
+	}
+
+	values := resp.Header.Values("WWW-Authenticate")
+	if len(values) == 0 {
+		return errors.New("header WWW-Authenticate not present")
+	}
+
+	actual, err := parseAuthChallenges(values)
+	if err != nil {
+		return fmt.Errorf("header WWW-Authenticate: %w", err)
+	}
+
+	mismatch := fmt.Errorf("header WWW-Authenticate: expected %s, got %s", httpReq.ExpectedAuthChallenge,
+		strings.Join(values, ", "))
+
+	for _, challenge := range actual {
+		if !strings.EqualFold(challenge.scheme, expected[0].scheme) {
+			continue
+		}
+
+		for name, value := range expected[0].params {
+			if challenge.params[name] != value {
+				return mismatch
+			}
+		}
+
+		return nil
+	}
+
+	return mismatch
+}
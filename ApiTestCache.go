@@ -0,0 +1,159 @@
+package gotest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ApiTestCacheBounds is the expected bounds of the caching headers of a response. The zero value of a field is not
+// checked.
+type ApiTestCacheBounds struct {
+	MinMaxAge      time.Duration // MinMaxAge is the minimum max-age directive of the Cache-Control header.
+	MaxMaxAge      time.Duration // MaxMaxAge is the maximum max-age directive of the Cache-Control header.
+	MaxAge         time.Duration // MaxAge is the maximum Age header, the time the response spent in caches.
+	RequireExpires bool          // RequireExpires requires a valid Expires header in the future.
+}
+
+// parseCacheControl function parses the directives of a Cache-Control header into their values by lowercase name,
+// an empty value for the directives without one.
+func parseCacheControl(header string) map[string]string {
+	directives := make(map[string]string)
+	for _, directive := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if name != "" {
+			directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+		}
+	}
+
+	return directives
+}
+
+// checkExpectedCacheControl function checks that the Cache-Control header of the response has every directive of
+// the ExpectedCacheControl of the test case, with the same value.
+func checkExpectedCacheControl(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if httpReq.ExpectedCacheControl == "" {
+		return nil
+	}
+
+	header := resp.Header.Get("Cache-Control")
+	actual := parseCacheControl(header)
+
+	expected := parseCacheControl(httpReq.ExpectedCacheControl)
+	for _, name := range sortedKeys(expected) {
+		value, isPresent := actual[name]
+		if !isPresent {
+			return fmt.Errorf("header Cache-Control: directive %s not present in %q", name, header)
+		}
+
+		if value != expected[name] {
+			return fmt.Errorf("header Cache-Control: directive %s expected %q, got %q", name, expected[name], value)
+		}
+	}
+
+	return nil
+}
+
+// checkExpectedCache function checks that the caching headers of the response are within the ExpectedCache bounds
+// of the test case.
+func checkExpectedCache(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	bounds := httpReq.ExpectedCache
+	if bounds == (ApiTestCacheBounds{}) {
+		return nil
+	}
+
+	if bounds.MinMaxAge > 0 || bounds.MaxMaxAge > 0 {
+		value, isPresent := parseCacheControl(resp.Header.Get("Cache-Control"))["max-age"]
+		if !isPresent {
+			return errors.New("header Cache-Control: directive max-age not present")
+		}
+
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("header Cache-Control: invalid max-age %q", value)
+		}
+
+		maxAge := time.Duration(seconds) * time.Second
+		if maxAge < bounds.MinMaxAge {
+			return fmt.Errorf("header Cache-Control: max-age %s, expected at least %s", maxAge, bounds.MinMaxAge)
+		}
+
+		if bounds.MaxMaxAge > 0 && maxAge > bounds.MaxMaxAge {
+			return fmt.Errorf("header Cache-Control: max-age %s, expected at most %s", maxAge, bounds.MaxMaxAge)
+		}
+	}
+
+	if bounds.MaxAge > 0 {
+		if value := resp.Header.Get("Age"); value != "" {
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("header Age: invalid value %q", value)
+			}
+
+			if age := time.Duration(seconds) * time.Second; age > bounds.MaxAge {
+				return fmt.Errorf("header Age: %s, expected at most %s", age, bounds.MaxAge)
+			}
+		}
+	}
+
+	if bounds.RequireExpires {
+		value := resp.Header.Get("Expires")
+		if value == "" {
+			return errors.New("header Expires not present")
+		}
+
+		expires, err := http.ParseTime(value)
+		if err != nil {
+			return fmt.Errorf("header Expires: invalid date %q", value)
+		}
+
+		if !expires.After(time.Now()) {
+			return fmt.Errorf("header Expires: %s is not in the future", value)
+		}
+	}
+
+	return nil
+}
+
+// AssertETagRevalidation function creates a single test case of the revalidation of a cached response: the request
+// is sent once, and must pass its checks and return an ETag header, then sent again with the ETag as If-None-Match,
+// and must return 304 Not Modified. The results of both requests are recorded in TestSteps, and the error names the
+// failed step, like a missing ETag or a wrong status.
+//
+// Example usage:
+//
+// ```
+// T.AssertETagRevalidation(ApiTestRequest{Details: "Get user", ApiUrl: "/users/1", ApiMethod: http.MethodGet,
+// ExpectedStatus: http.StatusOK})
+// ```
+func (h *ApiTest) AssertETagRevalidation(httpReq ApiTestRequest) {
+	steps := []ApiTestRequest{httpReq, {
+		Name:           httpReq.Name,
+		Details:        httpReq.Details + " (If-None-Match)",
+		ReqParam:       httpReq.ReqParam,
+		ApiUrl:         httpReq.ApiUrl,
+		ApiMethod:      httpReq.ApiMethod,
+		BearerToken:    httpReq.BearerToken,
+		Headers:        httpReq.Headers,
+		ExpectedStatus: http.StatusNotModified,
+	}}
+
+	failed := func(i int) string {
+		return fmt.Sprintf("step %d failed", i+1)
+	}
+
+	check := func(i int, result *ApiTestResult) *ApiTestError {
+		if i == 0 {
+			if steps[1].IfNoneMatch = result.TestResponse.Header.Get("ETag"); steps[1].IfNoneMatch == "" {
+				return responseError(ErrorAssertion, "step 1 failed: header ETag not present", result.TestResponse)
+			}
+		}
+
+		return nil
+	}
+
+	h.runSteps(httpReq, steps, failed, check)
+}
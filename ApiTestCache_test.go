@@ -0,0 +1,84 @@
+package gotest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAssertETagRevalidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   http.HandlerFunc
+		wantSteps int
+		wantError string
+	}{
+		{
+			name: "revalidated",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", `"v1"`)
+				if r.Header.Get("If-None-Match") == `"v1"` {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+
+				_, _ = w.Write([]byte(`{"id":1}`))
+			},
+			wantSteps: 2,
+		},
+		{
+			name: "missing ETag",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"id":1}`))
+			},
+			wantSteps: 1,
+			wantError: "step 1 failed: header ETag not present",
+		},
+		{
+			name: "not revalidated",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", `"v1"`)
+				_, _ = w.Write([]byte(`{"id":1}`))
+			},
+			wantSteps: 2,
+			wantError: "step 2 failed: unexpected status code 200 OK",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/users/1", test.handler)
+
+			h := InitApiTestWithHandler(mux)
+			defer h.Close()
+
+			h.Output = io.Discard
+			h.AssertETagRevalidation(ApiTestRequest{Name: "revalidation", Details: "Get user", ApiUrl: "/users/1",
+				ApiMethod: http.MethodGet, ExpectedStatus: http.StatusOK, Tags: []string{"cache"}})
+
+			result := h.Result[1]
+			if len(result.TestSteps) != test.wantSteps {
+				t.Errorf("expected %d steps, got %d", test.wantSteps, len(result.TestSteps))
+			}
+
+			if result.TestName != "revalidation" || len(result.TestTags) != 1 {
+				t.Errorf("expected the name and tags of the request, got %q and %v", result.TestName,
+					result.TestTags)
+			}
+
+			if test.wantError == "" {
+				if !result.TestStatus {
+					t.Errorf("expected the test case to pass, got %s", result.TestError)
+				}
+
+				return
+			}
+
+			if result.TestStatus || !strings.Contains(result.TestError.String(), test.wantError) {
+				t.Errorf("expected the error %q, got %q", test.wantError, result.TestError.String())
+			}
+		})
+	}
+}
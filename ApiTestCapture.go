@@ -0,0 +1,113 @@
+package gotest
+
+import "slices"
+
+// ApiTestCaptureMode is when the captured request and response of the test cases are retained, see CaptureOn.
+type ApiTestCaptureMode string
+
+// Modes of CaptureOn.
+const (
+	CaptureFailure ApiTestCaptureMode = "failure" // CaptureFailure retains the captures of the failed test cases only.
+	CaptureAlways  ApiTestCaptureMode = "always"  // CaptureAlways retains the captures of every test case.
+	CaptureNever   ApiTestCaptureMode = "never"   // CaptureNever retains no capture.
+)
+
+// retainCapture function drops the captured request and response of a result, and of its steps, unless CaptureOn
+// retains them.
+func (h *ApiTest) retainCapture(result *ApiTestResult) {
+	if h.CaptureOn == CaptureAlways {
+		return
+	}
+
+	for i := range result.TestSteps {
+		h.retainCapture(&result.TestSteps[i])
+	}
+
+	if h.CaptureOn == CaptureNever || result.TestStatus {
+		result.TestRequest, result.TestResponse = nil, nil
+	}
+}
+
+// retainBodies function records the size of the captured bodies of a result, numbered, against MaxRetainedBodyBytes,
+// and drops the bodies of the oldest results until they fit, or else the bodies of the result itself.
+func (h *ApiTest) retainBodies(result *ApiTestResult) {
+	size := bodyBytes(*result)
+	if h.MaxRetainedBodyBytes <= 0 || size == 0 {
+		return
+	}
+
+	for h.retainedBodyBytes+size > h.MaxRetainedBodyBytes && len(h.retainedBodies) > 0 {
+		number := h.retainedBodies[0]
+		h.retainedBodies = h.retainedBodies[1:]
+
+		if _, isRetained := h.retainedSizes[number]; !isRetained {
+			continue
+		}
+
+		h.releaseBodies(number)
+
+		evicted := h.Result[number]
+		dropBodies(&evicted)
+		h.Result[number] = evicted
+	}
+
+	if size > h.MaxRetainedBodyBytes {
+		dropBodies(result)
+		return
+	}
+
+	if h.retainedSizes == nil {
+		h.retainedSizes = make(map[int64]int64)
+	}
+
+	h.retainedBodies = append(h.retainedBodies, result.TestNumber)
+	h.retainedSizes[result.TestNumber] = size
+	h.retainedBodyBytes += size
+}
+
+// releaseBodies function stops counting the retained bodies of a result against MaxRetainedBodyBytes, like before
+// replacing it.
+func (h *ApiTest) releaseBodies(number int64) {
+	h.retainedBodyBytes -= h.retainedSizes[number]
+	delete(h.retainedSizes, number)
+}
+
+// bodyBytes function returns the total size of the captured bodies of a result and its steps.
+func bodyBytes(result ApiTestResult) int64 {
+	var size int64
+	if result.TestRequest != nil {
+		size += int64(len(result.TestRequest.Body))
+	}
+
+	if result.TestResponse != nil {
+		size += int64(len(result.TestResponse.Body))
+	}
+
+	for _, step := range result.TestSteps {
+		size += bodyBytes(step)
+	}
+
+	return size
+}
+
+// dropBodies function replaces the captures of a result and its steps by copies without bodies.
+func dropBodies(result *ApiTestResult) {
+	if result.TestRequest != nil {
+		request := *result.TestRequest
+		request.Body = nil
+		result.TestRequest = &request
+	}
+
+	if result.TestResponse != nil {
+		response := *result.TestResponse
+		response.Body = nil
+		result.TestResponse = &response
+	}
+
+	result.TestSteps = slices.Clone(result.TestSteps)
+	for i := range result.TestSteps {
+		dropBodies(&result.TestSteps[i])
+	}
+
+	result.TestBodiesDropped = true
+}
@@ -0,0 +1,173 @@
+package gotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// ApiTestCassetteMode is the mode of the cassette of CassettePath.
+type ApiTestCassetteMode int
+
+// Modes of the cassette.
+const (
+	CassetteAuto   ApiTestCassetteMode = iota // CassetteAuto replays the cassette when it exists, or else records it.
+	CassetteRecord                            // CassetteRecord sends the requests and records a new cassette.
+	CassetteReplay                            // CassetteReplay replays the cassette, and fails on unrecorded requests.
+)
+
+// cassetteInteraction is a recorded request with its response.
+type cassetteInteraction struct {
+	Method       string      `json:"method"`
+	Uri          string      `json:"uri"`
+	Body         []byte      `json:"body,omitempty"`
+	Status       int         `json:"status"`
+	Header       http.Header `json:"header"`
+	ResponseBody []byte      `json:"response_body,omitempty"`
+}
+
+// cassette is the RoundTripper recording or replaying the interactions of a cassette file.
+type cassette struct {
+	path         string                // path is the cassette file.
+	recording    bool                  // recording is whether the requests are sent and recorded, not replayed.
+	next         http.RoundTripper     // next is the transport sending the recorded requests.
+	interactions []cassetteInteraction // interactions is the recorded interactions, in order.
+	replayed     map[int]bool          // replayed is the interactions already replayed, by index.
+	mutex        sync.Mutex            // mutex guards interactions and replayed.
+
+	redactHeader func(header http.Header) http.Header // redactHeader redacts the recorded headers, see CassetteRedact.
+}
+
+// cassetteTransport function returns the cassette of CassettePath wrapping the transport, loading it once.
+func (h *ApiTest) cassetteTransport(next http.RoundTripper) (*cassette, error) {
+	h.cassetteMutex.Lock()
+	defer h.cassetteMutex.Unlock()
+
+	if h.cassette != nil {
+		return h.cassette, nil
+	}
+
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	c := &cassette{path: h.CassettePath, recording: h.CassetteMode == CassetteRecord, next: next,
+		replayed: make(map[int]bool), redactHeader: http.Header.Clone}
+	if h.CassetteRedact {
+		c.redactHeader = h.redactHeader
+	}
+
+	data, err := os.ReadFile(h.CassettePath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && h.CassetteMode == CassetteAuto:
+		c.recording = true
+	case err != nil && !c.recording:
+		return nil, fmt.Errorf("cannot read cassette %s: %w", h.CassettePath, err)
+	case err == nil && !c.recording:
+		if err := json.Unmarshal(data, &c.interactions); err != nil {
+			return nil, fmt.Errorf("invalid cassette %s: %w", h.CassettePath, err)
+		}
+	}
+
+	h.cassette = c
+	return c, nil
+}
+
+// RoundTrip function replays the first recorded interaction matching the method, URI and body of the request not
+// replayed yet, or the last matching one, or sends the request and records it when recording.
+func (c *cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if c.recording {
+		return c.record(req, body)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	match := -1
+	for i, interaction := range c.interactions {
+		if interaction.Method == req.Method && interaction.Uri == req.URL.RequestURI() &&
+			bytes.Equal(interaction.Body, body) {
+			match = i
+			if !c.replayed[i] {
+				break
+			}
+		}
+	}
+
+	if match < 0 {
+		return nil, fmt.Errorf("cassette %s: no recorded response for %s %s", c.path, req.Method,
+			req.URL.RequestURI())
+	}
+
+	c.replayed[match] = true
+	interaction := c.interactions[match]
+
+	return &http.Response{
+		Status:        strconv.Itoa(interaction.Status) + " " + http.StatusText(interaction.Status),
+		StatusCode:    interaction.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        interaction.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(interaction.ResponseBody)),
+		ContentLength: int64(len(interaction.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+// record function sends the request, records it with its response, and writes the cassette.
+func (c *cassette) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := c.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.interactions = append(c.interactions, cassetteInteraction{
+		Method:       req.Method,
+		Uri:          req.URL.RequestURI(),
+		Body:         body,
+		Status:       resp.StatusCode,
+		Header:       c.redactHeader(resp.Header),
+		ResponseBody: respBody,
+	})
+
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(c.path, append(data, '\n'), 0o644); err != nil {
+		return nil, fmt.Errorf("cannot write cassette %s: %w", c.path, err)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,73 @@
+package gotest
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	tests := []struct {
+		name         string
+		redact       bool
+		wantSecret   bool
+		wantReplayed bool
+	}{
+		{name: "real headers by default", redact: false, wantSecret: true, wantReplayed: true},
+		{name: "redacted headers with CassetteRedact", redact: true, wantSecret: false, wantReplayed: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+				http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret-session"})
+				w.Header().Set("X-Request-Id", "request-1")
+			})
+
+			path := filepath.Join(t.TempDir(), "cassette.json")
+			httpReq := ApiTestRequest{Details: "Login", ApiUrl: "/login", ApiMethod: http.MethodPost,
+				ExpectedStatus:      http.StatusOK,
+				ExpectedCookieAttrs: map[string]http.Cookie{"session": {Value: "secret-session"}}}
+
+			recorder := InitApiTestWithHandler(mux)
+			defer recorder.Close()
+
+			recorder.Output = io.Discard
+			recorder.CassettePath, recorder.CassetteMode, recorder.CassetteRedact = path, CassetteRecord, test.redact
+			recorder.CreateTest(httpReq)
+
+			if !recorder.Result[1].TestStatus {
+				t.Fatalf("expected the recorded test case to pass, got %s", recorder.Result[1].TestError)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := strings.Contains(string(data), "secret-session"); got != test.wantSecret {
+				t.Errorf("expected the Set-Cookie value recorded to be %t, got %t in %s", test.wantSecret, got, data)
+			}
+
+			if !strings.Contains(string(data), "request-1") {
+				t.Errorf("expected the X-Request-Id header to be recorded, got %s", data)
+			}
+
+			replayer := InitApiTestWithHandler(http.NewServeMux())
+			defer replayer.Close()
+
+			replayer.Output = io.Discard
+			replayer.CassettePath, replayer.CassetteMode = path, CassetteReplay
+			replayer.CreateTest(httpReq)
+
+			if got := replayer.Result[1].TestStatus; got != test.wantReplayed {
+				t.Errorf("expected the replayed test case to pass %t, got %t: %s", test.wantReplayed, got,
+					replayer.Result[1].TestError)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package gotest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxCompressionRatio is the maximum size of a compressed body, relative to its decompressed size, for its
+// compression to be effective.
+const maxCompressionRatio = 0.9
+
+// decompressBody function checks that the body of the response is gzip encoded and effectively compressed, and
+// returns it decompressed. Like the transparent decompression of http.Transport, it removes the Content-Encoding and
+// Content-Length headers of the response.
+func decompressBody(resp *http.Response, body []byte) ([]byte, error) {
+	encoding := resp.Header.Get("Content-Encoding")
+	if !strings.EqualFold(encoding, "gzip") {
+		return nil, fmt.Errorf("response not compressed: expected Content-Encoding gzip, got %q", encoding)
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip body: %w", err)
+	}
+
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip body: %w", err)
+	}
+
+	if float64(len(body)) > float64(len(decompressed))*maxCompressionRatio {
+		return nil, fmt.Errorf("compression not effective: %d bytes compressed, %d bytes decompressed", len(body),
+			len(decompressed))
+	}
+
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+
+	return decompressed, nil
+}
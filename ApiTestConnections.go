@@ -0,0 +1,80 @@
+package gotest
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+)
+
+// ApiTestConnectionStats is the counts of the connections of the requests, see TrackConnections.
+type ApiTestConnectionStats struct {
+	New    int64 // New is the count of requests sent on a new connection.
+	Reused int64 // Reused is the count of requests sent on a reused keep-alive connection.
+	Idle   int64 // Idle is the count of reused connections taken from the idle pool.
+}
+
+// traceConnections function returns the request with a trace counting its connection.
+func (h *ApiTest) traceConnections(req *http.Request) *http.Request {
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			h.connectionMutex.Lock()
+			defer h.connectionMutex.Unlock()
+
+			if !info.Reused {
+				h.connections.New++
+				return
+			}
+
+			h.connections.Reused++
+			if info.WasIdle {
+				h.connections.Idle++
+			}
+		},
+	}
+
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
+
+// ConnectionStats function returns the counts of the connections of the requests so far, when TrackConnections is
+// set.
+func (h *ApiTest) ConnectionStats() ApiTestConnectionStats {
+	h.connectionMutex.Lock()
+	defer h.connectionMutex.Unlock()
+
+	return h.connections
+}
+
+// serverConnections is the count of the concurrent connections of the Server, and its peak.
+type serverConnections struct {
+	mutex  sync.Mutex // mutex guards active and peak.
+	active int64      // active is the count of open connections.
+	peak   int64      // peak is the maximum count of open connections.
+}
+
+// connState function counts the connections of the Server, as its ConnState hook.
+func (c *serverConnections) connState(_ net.Conn, state http.ConnState) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	switch state {
+	case http.StateNew:
+		c.active++
+		c.peak = max(c.peak, c.active)
+	case http.StateHijacked, http.StateClosed:
+		c.active--
+	}
+}
+
+// ServerConnectionsPeak function returns the peak count of concurrent connections of the Server so far, see
+// MaxServerConnections.
+func (h *ApiTest) ServerConnectionsPeak() int64 {
+	if h.serverConnections == nil {
+		return 0
+	}
+
+	h.serverConnections.mutex.Lock()
+	defer h.serverConnections.mutex.Unlock()
+
+	return h.serverConnections.peak
+}
@@ -0,0 +1,124 @@
+package gotest
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// AssertConsistent function creates a single test case sending two requests in order, like a read from the primary
+// and one from a replica, or the same cached request twice, and passes when both pass their own checks and their
+// JSON bodies are equal, apart from the fields at the paths of ignoreFields. A path is a dot separated list of object
+// keys and array indexes, where "*" matches every key or index, like "updated_at" or "items.*.etag". The results of
+// both requests are recorded in TestSteps, and the error has a line-by-line diff of the bodies. The test case is named
+// after description, and is Soft and has the tags when either request is Soft or has them.
+//
+// Example usage:
+//
+// ```
+// T.AssertConsistent("Replica matches primary",
+// ApiTestRequest{Details: "Primary", ApiUrl: "/users/1", ApiMethod: http.MethodGet},
+// ApiTestRequest{Details: "Replica", ApiUrl: "/replica/users/1", ApiMethod: http.MethodGet},
+// "updated_at", "links.*.href")
+// ```
+func (h *ApiTest) AssertConsistent(description string, first ApiTestRequest, second ApiTestRequest,
+	ignoreFields ...string) {
+	tags := slices.Clone(first.Tags)
+	for _, tag := range second.Tags {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	test := ApiTestRequest{Name: description, Details: description, Soft: first.Soft || second.Soft, Tags: tags}
+
+	failed := func(i int) string {
+		return "request " + strconv.Itoa(i+1) + " failed"
+	}
+
+	check := func(i int, result *ApiTestResult) *ApiTestError {
+		if i == 0 {
+			return nil
+		}
+
+		firstBody, secondBody := result.TestSteps[0].TestResponse.Body, result.TestSteps[1].TestResponse.Body
+		if err := checkConsistent(firstBody, secondBody, ignoreFields); err != nil {
+			return responseError(ErrorAssertion, err.Error(), result.TestResponse)
+		}
+
+		return nil
+	}
+
+	h.runSteps(test, []ApiTestRequest{first, second}, failed, check)
+}
+
+// checkConsistent function checks that two JSON bodies are equal apart from the fields at the ignored paths.
+func checkConsistent(first []byte, second []byte, ignoreFields []string) error {
+	firstJson, err := decodeJson(first)
+	if err != nil {
+		return errors.New("request 1: " + err.Error())
+	}
+
+	secondJson, err := decodeJson(second)
+	if err != nil {
+		return errors.New("request 2: " + err.Error())
+	}
+
+	for _, path := range ignoreFields {
+		firstJson = removeJsonPath(firstJson, strings.Split(path, "."))
+		secondJson = removeJsonPath(secondJson, strings.Split(path, "."))
+	}
+
+	if jsonEqual(firstJson, secondJson) {
+		return nil
+	}
+
+	firstLines, secondLines := strings.Split(indentJson(firstJson), "\n"), strings.Split(indentJson(secondJson), "\n")
+	return errors.New("response bodies are not consistent\ndiff:\n" +
+		strings.Join(diffLines(firstLines, secondLines), "\n"))
+}
+
+// removeJsonPath function removes the fields at a path of a decoded JSON value, where "*" matches every key or
+// index, and returns the value. Missing fields are ignored.
+func removeJsonPath(value interface{}, keys []string) interface{} {
+	if len(keys) == 0 {
+		return value
+	}
+
+	key, isLast := keys[0], len(keys) == 1
+
+	switch node := value.(type) {
+	case map[string]interface{}:
+		for name, child := range node {
+			if key != "*" && key != name {
+				continue
+			}
+
+			if isLast {
+				delete(node, name)
+			} else {
+				node[name] = removeJsonPath(child, keys[1:])
+			}
+		}
+	case []interface{}:
+		if isLast {
+			kept := make([]interface{}, 0, len(node))
+			for i, child := range node {
+				if key != "*" && key != strconv.Itoa(i) {
+					kept = append(kept, child)
+				}
+			}
+
+			return kept
+		}
+
+		for i, child := range node {
+			if key == "*" || key == strconv.Itoa(i) {
+				node[i] = removeJsonPath(child, keys[1:])
+			}
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,153 @@
+package gotest
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Thresholds of the significant time changes of DiffRuns, when the current run has no PerfTolerancePct.
+const (
+	defaultTimeChangePct = 50
+	minTimeChange        = 10 * time.Millisecond
+)
+
+// ApiTestRunChange is a test case whose result changed between two runs.
+type ApiTestRunChange struct {
+	Key      string        // Key is the name, or else the description, of the test case, prefixed by its section.
+	Previous ApiTestResult // Previous is the result of the test case in the previous run.
+	Current  ApiTestResult // Current is the result of the test case in the current run.
+}
+
+// ApiTestRegressionReport is the changes of the results of the test cases between two runs, see DiffRuns.
+type ApiTestRegressionReport struct {
+	NewlyFailing []ApiTestRunChange // NewlyFailing is the test cases passed in the previous run, failed in the current.
+	NewlyPassing []ApiTestRunChange // NewlyPassing is the test cases failed in the previous run, passed in the current.
+	Slower       []ApiTestRunChange // Slower is the test cases significantly slower in the current run.
+	Faster       []ApiTestRunChange // Faster is the test cases significantly faster in the current run.
+	Added        []ApiTestResult    // Added is the test cases of the current run only.
+	Removed      []ApiTestResult    // Removed is the test cases of the previous run only.
+}
+
+// DiffRuns function compares the results of two runs of the same test cases, matched by section and name, or else
+// description, so they must be stable across the runs. A time change is significant when it is of more than the
+// PerfTolerancePct of the current run, 50% by default, and of more than 10ms.
+//
+// Example usage:
+//
+// ```
+// report := DiffRuns(baseline, T)
+// report.Write(os.Stdout)
+// if report.HasRegressions() {
+// os.Exit(1)
+// }
+// ```
+func DiffRuns(previous *ApiTest, current *ApiTest) ApiTestRegressionReport {
+	var report ApiTestRegressionReport
+
+	tolerancePct := current.PerfTolerancePct
+	if tolerancePct <= 0 {
+		tolerancePct = defaultTimeChangePct
+	}
+
+	previousResults := make(map[string]ApiTestResult)
+	for _, result := range previous.Results() {
+		previousResults[runKey(result)] = result
+	}
+
+	seen := make(map[string]bool)
+	for _, result := range current.Results() {
+		key := runKey(result)
+		seen[key] = true
+
+		before, isPresent := previousResults[key]
+		if !isPresent {
+			report.Added = append(report.Added, result)
+			continue
+		}
+
+		change := ApiTestRunChange{Key: key, Previous: before, Current: result}
+		switch {
+		case before.TestStatus && !result.TestStatus:
+			report.NewlyFailing = append(report.NewlyFailing, change)
+		case !before.TestStatus && result.TestStatus:
+			report.NewlyPassing = append(report.NewlyPassing, change)
+		}
+
+		if !before.TestStatus || !result.TestStatus {
+			continue
+		}
+
+		difference := result.TestTime - before.TestTime
+		limit := max(time.Duration(float64(before.TestTime)*tolerancePct/100), minTimeChange)
+
+		if difference > limit {
+			report.Slower = append(report.Slower, change)
+		} else if -difference > limit {
+			report.Faster = append(report.Faster, change)
+		}
+	}
+
+	for _, result := range previous.Results() {
+		if !seen[runKey(result)] {
+			report.Removed = append(report.Removed, result)
+		}
+	}
+
+	return report
+}
+
+// runKey function returns the key matching the results of a test case across runs.
+func runKey(result ApiTestResult) string {
+	key := testKey(result.TestName, result.TestDescription)
+	if result.TestSection != "" {
+		key = result.TestSection + " / " + key
+	}
+
+	return key
+}
+
+// HasRegressions function reports whether a test case newly fails or is significantly slower.
+func (r ApiTestRegressionReport) HasRegressions() bool {
+	return len(r.NewlyFailing) > 0 || len(r.Slower) > 0
+}
+
+// Write function writes the report to w as a list of changes by kind, or "No changes" without changes.
+func (r ApiTestRegressionReport) Write(w io.Writer) error {
+	var builder strings.Builder
+
+	for _, change := range r.NewlyFailing {
+		fmt.Fprintf(&builder, "\033[1;31mNewly failing\033[0;0m : %s: %s\n", change.Key,
+			change.Current.TestError.String())
+	}
+
+	for _, change := range r.NewlyPassing {
+		fmt.Fprintf(&builder, "\033[1;32mNewly passing\033[0;0m : %s\n", change.Key)
+	}
+
+	for _, change := range r.Slower {
+		fmt.Fprintf(&builder, "\033[1;33mSlower\033[0;0m        : %s: %s -> %s\n", change.Key, change.Previous.TestTime,
+			change.Current.TestTime)
+	}
+
+	for _, change := range r.Faster {
+		fmt.Fprintf(&builder, "\033[1;36mFaster\033[0;0m        : %s: %s -> %s\n", change.Key, change.Previous.TestTime,
+			change.Current.TestTime)
+	}
+
+	for _, result := range r.Added {
+		fmt.Fprintf(&builder, "Added         : %s\n", runKey(result))
+	}
+
+	for _, result := range r.Removed {
+		fmt.Fprintf(&builder, "Removed       : %s\n", runKey(result))
+	}
+
+	if builder.Len() == 0 {
+		builder.WriteString("No changes\n")
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
@@ -0,0 +1,80 @@
+package gotest
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// bodyExcerptSize is the maximum size of the body excerpt of the error of a test case.
+const bodyExcerptSize = 200
+
+// ApiTestErrorCategory is the category of the error of a test case.
+type ApiTestErrorCategory string
+
+// Categories of the errors of the test cases.
+const (
+	ErrorSetup     ApiTestErrorCategory = "setup"     // ErrorSetup is a request that could not be built or sent.
+	ErrorTransport ApiTestErrorCategory = "transport" // ErrorTransport is a response that could not be received.
+	ErrorStatus    ApiTestErrorCategory = "status"    // ErrorStatus is a response with an unexpected status code.
+	ErrorAssertion ApiTestErrorCategory = "assertion" // ErrorAssertion is a response that failed a check.
+	ErrorNotRun    ApiTestErrorCategory = "not run"   // ErrorNotRun is a test case that was not run.
+)
+
+// ApiTestError is the error of a test case, rendered the same way by every report.
+type ApiTestError struct {
+	Category    ApiTestErrorCategory `json:"category"`               // Category is the category of the error.
+	Message     string               `json:"message"`                // Message is the message of the error.
+	Status      int                  `json:"status,omitempty"`       // Status is the status code of the response.
+	BodyExcerpt string               `json:"body_excerpt,omitempty"` // BodyExcerpt is the start of the response body.
+}
+
+// newTestError function returns the error of a test case without response.
+func newTestError(category ApiTestErrorCategory, message string) *ApiTestError {
+	return &ApiTestError{Category: category, Message: message}
+}
+
+// responseError function returns the error of a test case with the status and an excerpt of the body of its
+// response.
+func responseError(category ApiTestErrorCategory, message string, resp *ApiTestResponseCapture) *ApiTestError {
+	testError := newTestError(category, message)
+	if resp == nil {
+		return testError
+	}
+
+	testError.Status = resp.Status
+
+	excerpt := resp.Body
+	if len(excerpt) > bodyExcerptSize {
+		excerpt = excerpt[:bodyExcerptSize]
+		for len(excerpt) > 0 && !utf8.Valid(excerpt) {
+			excerpt = excerpt[:len(excerpt)-1]
+		}
+
+		testError.BodyExcerpt = strings.ToValidUTF8(string(excerpt), "") + "..."
+	} else {
+		testError.BodyExcerpt = strings.ToValidUTF8(string(excerpt), "")
+	}
+
+	return testError
+}
+
+// Error function returns the message of the error.
+func (e *ApiTestError) Error() string {
+	return e.String()
+}
+
+// String function returns the message of the error, or an empty string without error.
+func (e *ApiTestError) String() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.Message
+}
+
+// wrap function returns a copy of the error with its message prefixed, like with the step of a flow.
+func (e *ApiTestError) wrap(prefix string) *ApiTestError {
+	wrapped := *e
+	wrapped.Message = prefix + ": " + e.Message
+	return &wrapped
+}
@@ -0,0 +1,63 @@
+package gotest
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// faultInjector is the handler of the Server, injecting latency and failures into the handler it wraps.
+type faultInjector struct {
+	handler     http.Handler  // handler is the wrapped handler.
+	mutex       sync.Mutex    // mutex guards latency and failureRate.
+	latency     time.Duration // latency is the delay added before every request is handled.
+	failureRate float64       // failureRate is the ratio, from 0 to 1, of requests failing with 503.
+}
+
+// ServeHTTP function handles a request with the wrapped handler, after the injected latency, unless it fails it.
+func (f *faultInjector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	latency, failureRate := f.latency, f.failureRate
+	f.mutex.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if failureRate > 0 && rand.Float64() < failureRate {
+		http.Error(w, "injected fault", http.StatusServiceUnavailable)
+		return
+	}
+
+	f.handler.ServeHTTP(w, r)
+}
+
+// InjectFaults function makes the Server delay every request by latency and fail a ratio failureRate, from 0 to 1,
+// of them with 503 Service Unavailable, to test the retries and timeouts of clients against it. It only affects the
+// Server of the ApiTest, InjectFaults(0, 0) turns the faults off.
+//
+// Example usage:
+//
+// ```
+// T.InjectFaults(200*time.Millisecond, 0.3)
+// T.Run(requests)
+// T.InjectFaults(0, 0)
+// ```
+func (h *ApiTest) InjectFaults(latency time.Duration, failureRate float64) {
+	if h.faults == nil {
+		return
+	}
+
+	h.faults.mutex.Lock()
+	defer h.faults.mutex.Unlock()
+
+	h.faults.latency, h.faults.failureRate = latency, failureRate
+}
@@ -0,0 +1,93 @@
+package gotest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+)
+
+// withIdempotencyKey function returns a copy of the headers of a request with a generated Idempotency-Key header,
+// unless it is already set.
+func (h *ApiTest) withIdempotencyKey(headers map[string]string) map[string]string {
+	for name := range headers {
+		if http.CanonicalHeaderKey(name) == "Idempotency-Key" {
+			return headers
+		}
+	}
+
+	key := randomUuid()
+	if h.IdempotencyKey != nil {
+		key = h.IdempotencyKey()
+	}
+
+	headers = maps.Clone(headers)
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+
+	headers["Idempotency-Key"] = key
+	return headers
+}
+
+// randomUuid function returns a random version 4 UUID.
+func randomUuid() string {
+	var uuid [16]byte
+	_, _ = rand.Read(uuid[:])
+
+	uuid[6] = uuid[6]&0x0f | 0x40
+	uuid[8] = uuid[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:])
+}
+
+// checkIdempotent function sends the request of a test case a second time, counting its retries in the result, and
+// checks that its response has the same status and body, decompressed like the first one, as the first one.
+func (h *ApiTest) checkIdempotent(httpReq ApiTestRequest, reqUrl string, reqBody []byte, token string,
+	result *ApiTestResult) error {
+	first := result.TestResponse
+
+	_, resp, err := h.send(httpReq, reqUrl, reqBody, token, result)
+	if err != nil {
+		return fmt.Errorf("idempotent request: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("idempotent request: %w", err)
+	}
+
+	if httpReq.ExpectCompressed {
+		if body, err = decompressBody(resp, body); err != nil {
+			return fmt.Errorf("idempotent request: %w", err)
+		}
+	}
+
+	if resp.StatusCode != first.Status {
+		return fmt.Errorf("idempotent request: status code %d, then %d", first.Status, resp.StatusCode)
+	}
+
+	if bytes.Equal(body, first.Body) {
+		return nil
+	}
+
+	firstJson, firstErr := unmarshalJson(first.Body)
+	secondJson, secondErr := unmarshalJson(body)
+	if firstErr != nil || secondErr != nil {
+		return fmt.Errorf("idempotent request: body %q, then %q", first.Body, body)
+	}
+
+	if err := jsonSubset(firstJson, secondJson, ""); err != nil {
+		return fmt.Errorf("idempotent request: body differs at %w", err)
+	}
+
+	if err := jsonSubset(secondJson, firstJson, ""); err != nil {
+		return fmt.Errorf("idempotent request: first body differs from the second at %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,301 @@
+package gotest
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// unmarshalJson function decodes JSON into its generic representation, with its numbers as json.Number instead of
+// float64 so that large integers, like 64-bit IDs, keep their precision.
+func unmarshalJson(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid data after top-level value at offset %d", decoder.InputOffset())
+	}
+
+	return value, nil
+}
+
+// decodeJson function decodes a JSON body into its generic representation.
+func decodeJson(body []byte) (interface{}, error) {
+	value, err := unmarshalJson(body)
+	if err != nil {
+		return nil, errors.New("response body is not valid JSON: " + err.Error())
+	}
+
+	return value, nil
+}
+
+// normalizeJson function converts a Go value into its generic JSON representation, so it can be compared with a
+// decoded JSON value.
+func normalizeJson(value interface{}) (interface{}, error) {
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalJson(jsonBytes)
+}
+
+// jsonEqual function reports whether two decoded JSON values are equal, comparing their numbers by value with full
+// precision, so 1.0 equals 1 but 9007199254740993 does not equal 9007199254740992.
+func jsonEqual(a interface{}, b interface{}) bool {
+	switch x := a.(type) {
+	case json.Number:
+		y, isNumber := b.(json.Number)
+		return isNumber && compareNumbers(x, y) == 0
+	case map[string]interface{}:
+		y, isObject := b.(map[string]interface{})
+		if !isObject || len(x) != len(y) {
+			return false
+		}
+
+		for key, value := range x {
+			other, isPresent := y[key]
+			if !isPresent || !jsonEqual(value, other) {
+				return false
+			}
+		}
+
+		return true
+	case []interface{}:
+		y, isArray := b.([]interface{})
+		if !isArray || len(x) != len(y) {
+			return false
+		}
+
+		for i := range x {
+			if !jsonEqual(x[i], y[i]) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// jsonSubset function checks that a decoded JSON value contains the expected one: the objects may have extra keys,
+// while the arrays must have the same length, with their items compared the same way. The error has the path of the
+// first missing or mismatched value, like "data.items.0.price".
+func jsonSubset(expected interface{}, actual interface{}, path string) error {
+	location := path
+	if location == "" {
+		location = "$"
+	}
+
+	switch x := expected.(type) {
+	case map[string]interface{}:
+		y, isObject := actual.(map[string]interface{})
+		if !isObject {
+			return fmt.Errorf("%s: expected an object, got %v", location, actual)
+		}
+
+		for _, key := range sortedKeys(x) {
+			other, isPresent := y[key]
+			if !isPresent {
+				return fmt.Errorf("%s: missing key %q", location, key)
+			}
+
+			if err := jsonSubset(x[key], other, joinJsonPath(path, key)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case []interface{}:
+		y, isArray := actual.([]interface{})
+		if !isArray || len(x) != len(y) {
+			return fmt.Errorf("%s: expected an array of %d items, got %v", location, len(x), actual)
+		}
+
+		for i := range x {
+			if err := jsonSubset(x[i], y[i], joinJsonPath(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	default:
+		if !jsonEqual(expected, actual) {
+			return fmt.Errorf("%s: expected %v, got %v", location, expected, actual)
+		}
+
+		return nil
+	}
+}
+
+// joinJsonPath function appends a key to a dot separated JSON path.
+func joinJsonPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// compareNumbers function compares two JSON numbers by value, and returns -1, 0 or 1 when a is respectively lower
+// than, equal to or greater than b. Numbers that cannot be parsed are compared as text.
+func compareNumbers(a json.Number, b json.Number) int {
+	x, isValid := new(big.Rat).SetString(a.String())
+	y, isOtherValid := new(big.Rat).SetString(b.String())
+	if !isValid || !isOtherValid {
+		return cmp.Compare(a, b)
+	}
+
+	return x.Cmp(y)
+}
+
+// lookupJsonPath function returns the value at a path of a decoded JSON value. The path is a dot separated list of
+// object keys and array indexes, optionally starting with "$", like "data.items.0.price".
+func lookupJsonPath(value interface{}, path string) (interface{}, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return value, nil
+	}
+
+	current := value
+	for _, key := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			child, isPresent := node[key]
+			if !isPresent {
+				return nil, fmt.Errorf("path %q not found: missing key %q", path, key)
+			}
+
+			current = child
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("path %q not found: invalid index %q", path, key)
+			}
+
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("path %q not found: %q is not an object or array", path, key)
+		}
+	}
+
+	return current, nil
+}
+
+// jsonErrorLocation function returns the 1-based line and column of a byte offset of a JSON document.
+func jsonErrorLocation(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+
+	line, column := 1, 1
+	for _, char := range data[:offset] {
+		if char == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+
+	return line, column
+}
+
+// checkValidJson function checks that the response body is valid JSON when ExpectValidJson is set.
+func checkValidJson(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if !httpReq.ExpectValidJson {
+		return nil
+	}
+
+	var value interface{}
+	err := json.Unmarshal(resp.Body, &value)
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line, column := jsonErrorLocation(resp.Body, max(syntaxErr.Offset-1, 0))
+		return fmt.Errorf("response body is not valid JSON at line %d, column %d: %s", line, column, syntaxErr)
+	}
+
+	if err != nil {
+		return errors.New("response body is not valid JSON: " + err.Error())
+	}
+
+	return nil
+}
+
+// checkExpectedSchemaOneOf function checks that the response body is valid against at least one of the
+// ExpectedSchemaOneOf JSON schemas of the test case, with the JsonSchemaValidator of the ApiTest. The error has the
+// validation errors of every schema tried.
+func checkExpectedSchemaOneOf(h *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if len(httpReq.ExpectedSchemaOneOf) == 0 {
+		return nil
+	}
+
+	if h.JsonSchemaValidator == nil {
+		return fmt.Errorf("cannot validate against %s: no JsonSchemaValidator",
+			strings.Join(httpReq.ExpectedSchemaOneOf, ", "))
+	}
+
+	mismatches := make([]string, 0, len(httpReq.ExpectedSchemaOneOf))
+	for _, schemaPath := range httpReq.ExpectedSchemaOneOf {
+		err := h.JsonSchemaValidator(schemaPath, resp.Body)
+		if err == nil {
+			return nil
+		}
+
+		mismatches = append(mismatches, fmt.Sprintf("%s: %s", schemaPath, err))
+	}
+
+	return fmt.Errorf("response body is valid against none of the %d expected schemas\n%s", len(mismatches),
+		strings.Join(mismatches, "\n"))
+}
+
+// compareJsonValues function compares two decoded JSON numbers or strings, and returns -1, 0 or 1 when a is
+// respectively lower than, equal to or greater than b.
+func compareJsonValues(a interface{}, b interface{}) (int, error) {
+	switch x := a.(type) {
+	case json.Number:
+		if y, isNumber := b.(json.Number); isNumber {
+			return compareNumbers(x, y), nil
+		}
+	case string:
+		if y, isString := b.(string); isString {
+			return cmp.Compare(x, y), nil
+		}
+	}
+
+	return 0, fmt.Errorf("cannot compare %v and %v", a, b)
+}
+
+// isJsonContentType function reports whether a content type is JSON, like application/json or
+// application/problem+json.
+func isJsonContentType(contentType string) bool {
+	media := mediaType(contentType)
+	return media == ContentTypeJson || strings.HasSuffix(media, "+json")
+}
+
+// checkBindResponse function decodes the JSON body of the response into BindResponse when it is set.
+func checkBindResponse(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if httpReq.BindResponse == nil || !isJsonContentType(resp.Header.Get("Content-Type")) {
+		return nil
+	}
+
+	if err := json.Unmarshal(resp.Body, httpReq.BindResponse); err != nil {
+		return errors.New("cannot bind response body: " + err.Error())
+	}
+
+	return nil
+}
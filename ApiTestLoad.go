@@ -0,0 +1,188 @@
+package gotest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ApiTestLoadRequest is a request of the traffic mix of RunLoad, sent in proportion to its Weight.
+type ApiTestLoadRequest struct {
+	Request ApiTestRequest // Request is the request to send.
+	Weight  int            // Weight is the relative weight of the request in the mix, like 70 for 70% of the traffic.
+}
+
+// ApiTestLoadStats is the statistics of the requests of RunLoad sent for a request of the mix.
+type ApiTestLoadStats struct {
+	Key      string        // Key is the name of the request, or else its description, or else its method and URL.
+	Requests int64         // Requests is the count of sent requests.
+	Errors   int64         // Errors is the count of sent requests whose test case failed.
+	Min      time.Duration // Min is the minimum time of the requests.
+	Mean     time.Duration // Mean is the mean time of the requests.
+	P50      time.Duration // P50 is the 50th percentile of the times of the requests.
+	P95      time.Duration // P95 is the 95th percentile of the times of the requests.
+	P99      time.Duration // P99 is the 99th percentile of the times of the requests.
+	Max      time.Duration // Max is the maximum time of the requests.
+
+	ErrorCategories map[ApiTestErrorCategory]int64 // ErrorCategories is the count of errors by category.
+}
+
+// ApiTestLoadReport is the report of RunLoad, with the statistics of each request of the mix in the mix order.
+type ApiTestLoadReport struct {
+	Duration time.Duration      // Duration is the time the load was run for.
+	Requests int64              // Requests is the count of sent requests.
+	Errors   int64              // Errors is the count of sent requests whose test case failed.
+	Stats    []ApiTestLoadStats // Stats is the statistics of each request of the mix.
+}
+
+// RunLoad function sends the requests of mix for duration from concurrency workers, each picking its next request
+// at random in proportion to the weights, like 70% GET /items, 20% POST /orders and 10% GET /users, to simulate a
+// realistic traffic mix. The RateLimit is shared by the workers. Every request is checked like a test case, but is
+// not recorded in the results: only the latency and error statistics of each request of the mix are reported.
+//
+// Example usage:
+//
+// ```
+// report, err := T.RunLoad([]ApiTestLoadRequest{
+// {Request: ApiTestRequest{Details: "List items", ApiUrl: "/items", ApiMethod: http.MethodGet}, Weight: 70},
+// {Request: ApiTestRequest{Details: "Order", ApiUrl: "/orders", ApiMethod: http.MethodPost}, Weight: 20},
+// {Request: ApiTestRequest{Details: "List users", ApiUrl: "/users", ApiMethod: http.MethodGet}, Weight: 10},
+// }, time.Minute, 8)
+// report.Write(os.Stdout)
+// ```
+func (h *ApiTest) RunLoad(mix []ApiTestLoadRequest, duration time.Duration,
+	concurrency int) (ApiTestLoadReport, error) {
+	totalWeight := 0
+	for i, loadReq := range mix {
+		if loadReq.Weight < 0 {
+			return ApiTestLoadReport{}, fmt.Errorf("mix[%d]: negative weight %d", i, loadReq.Weight)
+		}
+
+		totalWeight += loadReq.Weight
+	}
+
+	if totalWeight == 0 {
+		return ApiTestLoadReport{}, errors.New("mix has no request with a positive weight")
+	}
+
+	concurrency = max(concurrency, 1)
+
+	var mutex sync.Mutex
+	times := make([][]time.Duration, len(mix))
+	stats := make([]ApiTestLoadStats, len(mix))
+	for i, loadReq := range mix {
+		stats[i].Key = loadKey(loadReq.Request)
+		stats[i].ErrorCategories = make(map[ApiTestErrorCategory]int64)
+	}
+
+	startTime := time.Now()
+	deadline := startTime.Add(duration)
+
+	var waitGroup sync.WaitGroup
+	for worker := 0; worker < concurrency; worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+
+			for time.Now().Before(deadline) {
+				i := pickWeighted(mix, rand.Intn(totalWeight))
+				result := h.runTest(mix[i].Request)
+
+				mutex.Lock()
+				stats[i].Requests++
+				times[i] = append(times[i], result.TestTime)
+				if !result.TestStatus {
+					stats[i].Errors++
+					if result.TestError != nil {
+						stats[i].ErrorCategories[result.TestError.Category]++
+					}
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	waitGroup.Wait()
+
+	report := ApiTestLoadReport{Duration: time.Since(startTime)}
+	for i := range stats {
+		slices.Sort(times[i])
+		stats[i].setTimes(times[i])
+
+		report.Requests += stats[i].Requests
+		report.Errors += stats[i].Errors
+	}
+
+	report.Stats = stats
+	return report, nil
+}
+
+// loadKey function returns the key of a request of the mix of RunLoad.
+func loadKey(httpReq ApiTestRequest) string {
+	if key := testKey(httpReq.Name, httpReq.Details); key != "" {
+		return key
+	}
+
+	return httpReq.ApiMethod + " " + httpReq.ApiUrl
+}
+
+// pickWeighted function returns the index of the request of the mix at a position lower than the total weight.
+func pickWeighted(mix []ApiTestLoadRequest, position int) int {
+	for i, loadReq := range mix {
+		if position < loadReq.Weight {
+			return i
+		}
+
+		position -= loadReq.Weight
+	}
+
+	return len(mix) - 1
+}
+
+// setTimes function sets the time statistics from the sorted times of the requests.
+func (s *ApiTestLoadStats) setTimes(times []time.Duration) {
+	if len(times) == 0 {
+		return
+	}
+
+	var total time.Duration
+	for _, elapsed := range times {
+		total += elapsed
+	}
+
+	s.Min, s.Max = times[0], times[len(times)-1]
+	s.Mean = total / time.Duration(len(times))
+	s.P50, s.P95, s.P99 = percentile(times, 50), percentile(times, 95), percentile(times, 99)
+}
+
+// Throughput function returns the count of requests sent per second.
+func (r ApiTestLoadReport) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+
+	return float64(r.Requests) / r.Duration.Seconds()
+}
+
+// Write function writes the report to w as a table with a row per request of the mix.
+func (r ApiTestLoadReport) Write(w io.Writer) error {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%-30s │ %8s │ %8s │ %12s │ %12s │ %12s │ %12s\n", "Request", "Count", "Errors", "Mean",
+		"P50", "P95", "P99")
+
+	for _, stats := range r.Stats {
+		fmt.Fprintf(&builder, "%-30s │ %8d │ %8d │ %12s │ %12s │ %12s │ %12s\n", stats.Key, stats.Requests,
+			stats.Errors, stats.Mean, stats.P50, stats.P95, stats.P99)
+	}
+
+	fmt.Fprintf(&builder, "\nTotal requests of load test: %d (%d errors) in %s, %.2f requests per second\n",
+		r.Requests, r.Errors, r.Duration.Round(time.Millisecond), r.Throughput())
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
@@ -0,0 +1,59 @@
+package gotest
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ApiTestLogger is the logger of the diagnostic output of an ApiTest, like the request and response dumps. It is
+// implemented by *slog.Logger.
+type ApiTestLogger interface {
+	Debug(msg string, args ...interface{}) // Debug logs at the debug level.
+	Info(msg string, args ...interface{})  // Info logs at the info level.
+	Warn(msg string, args ...interface{})  // Warn logs at the warn level.
+	Error(msg string, args ...interface{}) // Error logs at the error level.
+}
+
+// writerLogger is the default ApiTestLogger, it writes every message as is, followed by its "key=value" arguments,
+// to a writer.
+type writerLogger struct {
+	w io.Writer // w is the writer of the messages.
+}
+
+// Debug function writes a debug message.
+func (l writerLogger) Debug(msg string, args ...interface{}) { l.write(msg, args) }
+
+// Info function writes an info message.
+func (l writerLogger) Info(msg string, args ...interface{}) { l.write(msg, args) }
+
+// Warn function writes a warn message.
+func (l writerLogger) Warn(msg string, args ...interface{}) { l.write(msg, args) }
+
+// Error function writes an error message.
+func (l writerLogger) Error(msg string, args ...interface{}) { l.write(msg, args) }
+
+// write function writes a message followed by its "key=value" arguments.
+func (l writerLogger) write(msg string, args []interface{}) {
+	var builder strings.Builder
+	builder.WriteString(msg)
+
+	for i := 0; i < len(args); i += 2 {
+		if i+1 < len(args) {
+			fmt.Fprintf(&builder, " %v=%v", args[i], args[i+1])
+		} else {
+			fmt.Fprintf(&builder, " %v", args[i])
+		}
+	}
+
+	fmt.Fprintln(l.w, builder.String())
+}
+
+// logger function returns the logger of the diagnostic output.
+func (h *ApiTest) logger() ApiTestLogger {
+	if h.Logger == nil {
+		return writerLogger{w: h.output()}
+	}
+
+	return h.Logger
+}
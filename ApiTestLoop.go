@@ -0,0 +1,96 @@
+package gotest
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ApiTestLoopStats is the statistics of the cycles of RunLoop.
+type ApiTestLoopStats struct {
+	Cycles       int64 // Cycles is the count of cycles run.
+	PassedCycles int64 // PassedCycles is the count of cycles whose run test cases all passed, except the Soft ones.
+	Tests        int64 // Tests is the count of test cases run across the cycles.
+	PassedTests  int64 // PassedTests is the count of passed test cases across the cycles.
+}
+
+// Uptime function returns the percentage of the passed cycles.
+func (s ApiTestLoopStats) Uptime() float64 {
+	if s.Cycles == 0 {
+		return 0
+	}
+
+	return float64(s.PassedCycles) * 100 / float64(s.Cycles)
+}
+
+// RunLoop function runs the requests every interval, starting immediately, until stop is closed, like a synthetic
+// monitor, and returns the statistics of the cycles. Every cycle replaces the results of the previous one and has its
+// own MaxSuiteDuration, so a cycle whose test cases were all not run does not pass. Every cycle logs its summary and
+// failed test cases to the Logger, only when a test case changed status if LoopChangesOnly is set.
+//
+// Example usage:
+//
+// ```
+// stop := make(chan struct{})
+// time.AfterFunc(time.Hour, func() { close(stop) })
+// stats := T.RunLoop(requests, time.Minute, stop)
+// fmt.Printf("uptime: %.2f%%\n", stats.Uptime())
+// ```
+func (h *ApiTest) RunLoop(requests []ApiTestRequest, interval time.Duration, stop <-chan struct{}) ApiTestLoopStats {
+	var stats ApiTestLoopStats
+	var previousStatuses []bool
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		h.Tests, h.PassedTests, h.FailedTests, h.SoftFailed, h.NotRunTests = 0, 0, 0, 0, 0
+		h.Result = make(map[int64]ApiTestResult)
+		h.retainedBodies, h.retainedSizes, h.retainedBodyBytes = nil, nil, 0
+		h.requestKeys = nil
+		h.suiteStart = time.Time{}
+
+		h.Run(requests)
+
+		stats.Cycles++
+		stats.Tests += h.Tests
+		stats.PassedTests += h.PassedTests
+		if h.FailedTests == h.SoftFailed && (h.Tests == 0 || h.NotRunTests < h.Tests) {
+			stats.PassedCycles++
+		}
+
+		numbers := h.resultNumbers()
+
+		statuses := make([]bool, 0, len(numbers))
+		for _, number := range numbers {
+			statuses = append(statuses, h.Result[number].TestStatus)
+		}
+
+		if !h.LoopChangesOnly || !slices.Equal(statuses, previousStatuses) {
+			h.logCycle(stats, numbers)
+		}
+
+		previousStatuses = statuses
+
+		select {
+		case <-stop:
+			return stats
+		case <-ticker.C:
+		}
+	}
+}
+
+// logCycle function logs the summary and the failed test cases of a cycle of RunLoop.
+func (h *ApiTest) logCycle(stats ApiTestLoopStats, numbers []int64) {
+	h.logger().Info("cycle", "cycle", stats.Cycles, "passed", h.PassedTests, "failed", h.FailedTests,
+		"soft_failed", h.SoftFailed,
+		"uptime", fmt.Sprintf("%.2f%%", stats.Uptime()))
+
+	for _, number := range numbers {
+		result := h.Result[number]
+		if !result.TestStatus {
+			h.logger().Warn("failed", "number", number, "description", result.TestDescription,
+				"error", result.TestError.String())
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package gotest
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunLoopMaxSuiteDurationPerCycle(t *testing.T) {
+	var requests atomic.Int64
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(20 * time.Millisecond)
+	})
+
+	h := InitApiTestWithHandler(mux)
+	defer h.Close()
+
+	h.Output = io.Discard
+	h.MaxSuiteDuration = 10 * time.Millisecond
+
+	stop := make(chan struct{})
+	time.AfterFunc(200*time.Millisecond, func() { close(stop) })
+
+	stats := h.RunLoop([]ApiTestRequest{
+		{Details: "Slow", ApiUrl: "/slow", ApiMethod: http.MethodGet, ExpectedStatus: http.StatusOK},
+		{Details: "Not run", ApiUrl: "/slow", ApiMethod: http.MethodGet, ExpectedStatus: http.StatusOK},
+	}, 30*time.Millisecond, stop)
+
+	if stats.Cycles < 2 {
+		t.Fatalf("expected at least 2 cycles, got %d", stats.Cycles)
+	}
+
+	if got := requests.Load(); got != stats.Cycles {
+		t.Errorf("expected a request per cycle, got %d requests in %d cycles", got, stats.Cycles)
+	}
+
+	if stats.PassedCycles != stats.Cycles {
+		t.Errorf("expected %d passed cycles, got %d", stats.Cycles, stats.PassedCycles)
+	}
+}
+
+func TestRunLoopAllNotRunCycleNotPassed(t *testing.T) {
+	h := InitApiTestWithHandler(http.NewServeMux())
+	defer h.Close()
+
+	h.Output = io.Discard
+	h.MaxSuiteDuration = time.Nanosecond
+
+	stop := make(chan struct{})
+	close(stop)
+
+	stats := h.RunLoop([]ApiTestRequest{
+		{Details: "Not run", ApiUrl: "/", ApiMethod: http.MethodGet, ExpectedStatus: http.StatusNotFound},
+	}, time.Hour, stop)
+
+	if stats.Cycles != 1 || stats.PassedCycles != 0 {
+		t.Errorf("expected 1 cycle and no passed cycle, got %d cycles and %d passed", stats.Cycles,
+			stats.PassedCycles)
+	}
+}
@@ -0,0 +1,75 @@
+package gotest
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ApiTestFile is a file uploaded in the multipart body of a request.
+type ApiTestFile struct {
+	Field       string // Field is the name of the form field of the file.
+	FileName    string // FileName is the name of the file, defaults to the base name of Path.
+	ContentType string // ContentType is the content type of the file, defaults to application/octet-stream.
+	Content     []byte // Content is the content of the file, read from Path if nil.
+	Path        string // Path is the path of the file on disk, used when Content is nil.
+}
+
+// multipartQuoteEscaper escapes the quoted names of the Content-Disposition of parts.
+var multipartQuoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// encodeMultipart function encodes the form fields, sorted by name, and then the files, in order, as a
+// multipart/form-data body, and returns it with its content type and boundary.
+func encodeMultipart(formFields map[string]string, files []ApiTestFile) ([]byte, string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	for _, name := range sortedKeys(formFields) {
+		if err := writer.WriteField(name, formFields[name]); err != nil {
+			return nil, "", err
+		}
+	}
+
+	for _, file := range files {
+		content := file.Content
+		if content == nil {
+			var err error
+			if content, err = os.ReadFile(file.Path); err != nil {
+				return nil, "", err
+			}
+		}
+
+		fileName := file.FileName
+		if fileName == "" && file.Path != "" {
+			fileName = filepath.Base(file.Path)
+		}
+
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", `form-data; name="`+multipartQuoteEscaper.Replace(file.Field)+
+			`"; filename="`+multipartQuoteEscaper.Replace(fileName)+`"`)
+		header.Set("Content-Type", contentType)
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if _, err := part.Write(content); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return body.Bytes(), writer.FormDataContentType(), nil
+}
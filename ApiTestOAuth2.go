@@ -0,0 +1,82 @@
+package gotest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// oauth2ExpirySkew is the time before its expiry an OAuth2 token is fetched again, so it does not expire in flight.
+const oauth2ExpirySkew = 10 * time.Second
+
+// ApiTestOAuth2 is the client credentials of the OAuth2 client credentials grant, see RFC 6749.
+type ApiTestOAuth2 struct {
+	TokenUrl     string // TokenUrl is the URL of the token endpoint.
+	ClientId     string // ClientId is the identifier of the client.
+	ClientSecret string // ClientSecret is the secret of the client.
+	Scope        string // Scope is the space separated scopes of the token, if any.
+}
+
+// oauth2TokenResponse is the response of the token endpoint.
+type oauth2TokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// fetchOAuth2Token function fetches an access token with the OAuth2 client credentials, authenticating the client
+// with HTTP Basic, and returns it with its expiry time, zero when the token does not expire.
+func (h *ApiTest) fetchOAuth2Token() (string, time.Time, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if h.OAuth2.Scope != "" {
+		form.Set("scope", h.OAuth2.Scope)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, h.OAuth2.TokenUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", ContentTypeJson)
+	req.SetBasicAuth(url.QueryEscape(h.OAuth2.ClientId), url.QueryEscape(h.OAuth2.ClientSecret))
+
+	resp, err := h.client().Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	var token oauth2TokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", time.Time{}, fmt.Errorf("token endpoint responded %s with an invalid body", resp.Status)
+	}
+
+	if token.Error != "" {
+		return "", time.Time{}, fmt.Errorf("token endpoint responded %s: %s", resp.Status,
+			strings.TrimSpace(token.Error+" "+token.ErrorDescription))
+	}
+
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return "", time.Time{}, errors.New("token endpoint responded " + resp.Status + " without an access token")
+	}
+
+	var expiry time.Time
+	if token.ExpiresIn > 0 {
+		expiry = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - oauth2ExpirySkew)
+	}
+
+	return token.AccessToken, expiry, nil
+}
@@ -0,0 +1,108 @@
+package gotest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// testKey function returns the key identifying a test case, like in the performance baseline, its name or else its
+// description.
+func testKey(name string, description string) string {
+	if name != "" {
+		return name
+	}
+
+	return description
+}
+
+// loadPerfBaseline function loads the performance baseline once. A missing file is an empty baseline.
+func (h *ApiTest) loadPerfBaseline() (map[string]time.Duration, error) {
+	h.perfMutex.Lock()
+	defer h.perfMutex.Unlock()
+
+	if h.perfBaseline != nil {
+		return h.perfBaseline, nil
+	}
+
+	baseline := make(map[string]time.Duration)
+
+	data, err := os.ReadFile(h.PerfBaseline)
+	if errors.Is(err, fs.ErrNotExist) {
+		h.perfBaseline = baseline
+		return baseline, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var times map[string]string
+	if err := json.Unmarshal(data, &times); err != nil {
+		return nil, fmt.Errorf("invalid performance baseline %s: %w", h.PerfBaseline, err)
+	}
+
+	for key, text := range times {
+		if baseline[key], err = time.ParseDuration(text); err != nil {
+			return nil, fmt.Errorf("invalid performance baseline %s: %q: %w", h.PerfBaseline, key, err)
+		}
+	}
+
+	h.perfBaseline = baseline
+	return baseline, nil
+}
+
+// checkPerfBaseline function checks that a test case is not slower than its baseline time by more than
+// PerfTolerancePct percent.
+func (h *ApiTest) checkPerfBaseline(httpReq ApiTestRequest, elapsed time.Duration) error {
+	if h.PerfBaseline == "" || h.UpdatePerfBaseline {
+		return nil
+	}
+
+	baseline, err := h.loadPerfBaseline()
+	if err != nil {
+		return err
+	}
+
+	expected, isPresent := baseline[testKey(httpReq.Name, httpReq.Details)]
+	if !isPresent {
+		return nil
+	}
+
+	limit := time.Duration(float64(expected) * (1 + h.PerfTolerancePct/100))
+	if elapsed > limit {
+		return fmt.Errorf("slower than baseline: took %s, baseline %s (+%g%% = %s)", elapsed, expected,
+			h.PerfTolerancePct, limit)
+	}
+
+	return nil
+}
+
+// SavePerfBaseline function writes the times of the passed test cases to the PerfBaseline file, by name or else
+// description. It is called by DumpApiTestResult when UpdatePerfBaseline is set.
+//
+// Example usage:
+//
+// ```
+// T.PerfBaseline = "testdata/perf.json"
+// T.UpdatePerfBaseline = os.Getenv("UPDATE_BASELINE") != ""
+// ```
+func (h *ApiTest) SavePerfBaseline() error {
+	times := make(map[string]string)
+	for _, number := range h.resultNumbers() {
+		result := h.Result[number]
+		if result.TestStatus {
+			times[testKey(result.TestName, result.TestDescription)] = result.TestTime.String()
+		}
+	}
+
+	data, err := json.MarshalIndent(times, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(h.PerfBaseline, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,82 @@
+package gotest
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ApiTestQueryStyle is the encoding style of the parameters with several values of a query string.
+type ApiTestQueryStyle string
+
+const (
+	QueryStyleRepeat  ApiTestQueryStyle = "repeat"  // QueryStyleRepeat repeats the key, like "?id=1&id=2".
+	QueryStyleComma   ApiTestQueryStyle = "comma"   // QueryStyleComma joins the values, like "?id=1,2".
+	QueryStyleBracket ApiTestQueryStyle = "bracket" // QueryStyleBracket repeats the key with "[]", like "?id[]=1&id[]=2".
+)
+
+// GenerateQuery function generates a query string from parameters with several values, like url.Values, sorted by
+// key and encoded in a style, QueryStyleRepeat if empty.
+//
+// Example usage:
+//
+// ```
+// query, err := GenerateQuery(map[string][]string{"id": {"1", "2"}}, QueryStyleComma) // "?id=1,2"
+// ```
+func GenerateQuery(values map[string][]string, style ApiTestQueryStyle) (string, error) {
+	if style == "" {
+		style = QueryStyleRepeat
+	}
+
+	if style != QueryStyleRepeat && style != QueryStyleComma && style != QueryStyleBracket {
+		return "", fmt.Errorf("unsupported query style %q", style)
+	}
+
+	var queryParts []string
+	for _, key := range sortedKeys(values) {
+		encodedKey := url.QueryEscape(key)
+
+		encodedValues := make([]string, 0, len(values[key]))
+		for _, value := range values[key] {
+			encodedValues = append(encodedValues, url.QueryEscape(value))
+		}
+
+		switch style {
+		case QueryStyleComma:
+			queryParts = append(queryParts, encodedKey+"="+strings.Join(encodedValues, ","))
+		case QueryStyleBracket:
+			for _, encodedValue := range encodedValues {
+				queryParts = append(queryParts, encodedKey+"[]="+encodedValue)
+			}
+		default:
+			for _, encodedValue := range encodedValues {
+				queryParts = append(queryParts, encodedKey+"="+encodedValue)
+			}
+		}
+	}
+
+	if len(queryParts) == 0 {
+		return "", nil
+	}
+
+	return "?" + strings.Join(queryParts, "&"), nil
+}
+
+// generateReqParam function generates the query string of the ReqParam of a request: a string as is, a
+// map[string]string with GeneratePathParam, and a map[string][]string or url.Values with GenerateQuery.
+func generateReqParam(reqParam interface{}, style ApiTestQueryStyle) (string, error) {
+	switch value := reqParam.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case map[string]string:
+		return GeneratePathParam(value), nil
+	case map[string][]string:
+		return GenerateQuery(value, style)
+	case url.Values:
+		return GenerateQuery(value, style)
+	default:
+		return "", fmt.Errorf("unsupported ReqParam of type %T", reqParam)
+	}
+}
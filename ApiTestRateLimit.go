@@ -0,0 +1,98 @@
+package gotest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// rateLimitHeaders function returns the limit and remaining count of the X-RateLimit-Limit and X-RateLimit-Remaining
+// headers of a response.
+func rateLimitHeaders(resp *ApiTestResponseCapture) (int, int, error) {
+	var values [2]int
+	for i, name := range []string{"X-RateLimit-Limit", "X-RateLimit-Remaining"} {
+		text := resp.Header.Get(name)
+		if text == "" {
+			return 0, 0, fmt.Errorf("missing header %s", name)
+		}
+
+		value, err := strconv.Atoi(strings.TrimSpace(text))
+		if err != nil || value < 0 {
+			return 0, 0, fmt.Errorf("invalid header %s: %q", name, text)
+		}
+
+		values[i] = value
+	}
+
+	if values[1] > values[0] {
+		return 0, 0, fmt.Errorf("X-RateLimit-Remaining %d exceeds X-RateLimit-Limit %d", values[1], values[0])
+	}
+
+	return values[0], values[1], nil
+}
+
+// checkRateLimitHeaders function checks that the response has valid X-RateLimit-Limit and X-RateLimit-Remaining
+// headers when AssertRateLimitHeaders is set.
+func checkRateLimitHeaders(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if !httpReq.AssertRateLimitHeaders {
+		return nil
+	}
+
+	_, _, err := rateLimitHeaders(resp)
+	return err
+}
+
+// AssertRateLimit function creates a single test case sending the request requests times in a row, and passes when
+// every response passes its checks and has valid rate limit headers, see AssertRateLimitHeaders, whose
+// X-RateLimit-Remaining decreases by one from a response to the next, or resets to X-RateLimit-Limit minus one when
+// a new window starts. The count of requests should stay within the limit, since the rejected requests fail their
+// checks. The results of the requests are recorded in TestSteps, and the error has the observed sequence.
+//
+// Example usage:
+//
+// ```
+// T.AssertRateLimit(ApiTestRequest{Details: "List items rate limit", ApiUrl: "/items", ApiMethod: http.MethodGet}, 5)
+// ```
+func (h *ApiTest) AssertRateLimit(httpReq ApiTestRequest, requests int) {
+	httpReq.AssertRateLimitHeaders = true
+
+	if requests < 1 {
+		h.addTestResult(ApiTestResult{TestName: httpReq.Name, TestDescription: httpReq.Details,
+			TestSoft: httpReq.Soft, TestTags: httpReq.Tags,
+			TestError: newTestError(ErrorSetup, fmt.Sprintf("invalid count of requests %d", requests))})
+		return
+	}
+
+	var observed []string
+	previous := -1
+
+	failed := func(i int) string {
+		prefix := fmt.Sprintf("request %d failed", i+1)
+		if len(observed) > 0 {
+			prefix += " after observing " + strings.Join(observed, ", ")
+		}
+
+		return prefix
+	}
+
+	check := func(i int, result *ApiTestResult) *ApiTestError {
+		limit, remaining, _ := rateLimitHeaders(result.TestResponse)
+		observed = append(observed, strconv.Itoa(remaining))
+
+		if previous >= 0 && remaining != previous-1 && remaining != limit-1 {
+			return responseError(ErrorAssertion, fmt.Sprintf(
+				"X-RateLimit-Remaining did not decrease by one at request %d: observed %s", i+1,
+				strings.Join(observed, ", ")), result.TestResponse)
+		}
+
+		previous = remaining
+		return nil
+	}
+
+	steps := make([]ApiTestRequest, requests)
+	for i := range steps {
+		steps[i] = httpReq
+	}
+
+	h.runSteps(httpReq, steps, failed, check)
+}
@@ -0,0 +1,77 @@
+package gotest
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAssertRateLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		remaining []string
+		requests  int
+		wantSteps int
+		wantError string
+	}{
+		{name: "decreasing", remaining: []string{"4", "3", "2"}, requests: 3, wantSteps: 3},
+		{name: "new window", remaining: []string{"1", "0", "4"}, requests: 3, wantSteps: 3},
+		{
+			name:      "not decreasing",
+			remaining: []string{"3", "3", "2"},
+			requests:  3,
+			wantSteps: 2,
+			wantError: "X-RateLimit-Remaining did not decrease by one at request 2: observed 3, 3",
+		},
+		{
+			name:      "missing header",
+			remaining: []string{"4", ""},
+			requests:  2,
+			wantSteps: 2,
+			wantError: "request 2 failed after observing 4",
+		},
+		{name: "no request", requests: 0, wantError: "invalid count of requests 0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var count int
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/items", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-RateLimit-Limit", strconv.Itoa(5))
+				if remaining := test.remaining[count]; remaining != "" {
+					w.Header().Set("X-RateLimit-Remaining", remaining)
+				}
+
+				count++
+			})
+
+			h := InitApiTestWithHandler(mux)
+			defer h.Close()
+
+			h.Output = io.Discard
+			h.AssertRateLimit(ApiTestRequest{Details: "List items", ApiUrl: "/items", ApiMethod: http.MethodGet,
+				ExpectedStatus: http.StatusOK}, test.requests)
+
+			result := h.Result[1]
+			if len(result.TestSteps) != test.wantSteps {
+				t.Errorf("expected %d steps, got %d", test.wantSteps, len(result.TestSteps))
+			}
+
+			if test.wantError == "" {
+				if !result.TestStatus {
+					t.Errorf("expected the test case to pass, got %s", result.TestError)
+				}
+
+				return
+			}
+
+			if result.TestStatus || !strings.Contains(result.TestError.String(), test.wantError) {
+				t.Errorf("expected the error %q, got %q", test.wantError, result.TestError.String())
+			}
+		})
+	}
+}
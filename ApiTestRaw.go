@@ -0,0 +1,104 @@
+package gotest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+)
+
+// CreateTestRaw function creates a new test case for a request built by hand, like with trailers or unusual headers
+// that ApiTestRequest cannot express. Its URL must already be complete, since the BaseUrl, variables, query
+// parameters and bearer token are not applied. The request is sent, timed and recorded like the other test cases,
+// and passes when its response has the expected status, or any status when expectedStatus is 0. A body without
+// GetBody is read once to be recorded, and then sent from the bytes read.
+//
+// Example usage:
+//
+// ```
+// req, _ := http.NewRequest(http.MethodPut, T.Server.URL+"/files/1", body)
+// req.Trailer = http.Header{"Checksum": {checksum}}
+// T.CreateTestRaw(req, http.StatusNoContent, "Upload with checksum trailer")
+// ```
+func (h *ApiTest) CreateTestRaw(req *http.Request, expectedStatus int, details string) {
+	result := ApiTestResult{TestDescription: details}
+	httpReq := ApiTestRequest{Details: details, ApiUrl: req.URL.String(), ApiMethod: req.Method}
+	if expectedStatus != 0 {
+		httpReq.ExpectedStatus = expectedStatus
+	}
+
+	var reqBody []byte
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			reqBody, _ = io.ReadAll(body)
+			body.Close()
+		}
+	} else if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			result.TestError = newTestError(ErrorSetup, "cannot read request body: "+err.Error())
+			h.addTestResult(result)
+			return
+		}
+
+		reqBody = body
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(reqBody)), nil }
+	}
+
+	result.TestRequest = &ApiTestRequestCapture{
+		Method:           req.Method,
+		Url:              req.URL.String(),
+		Header:           req.Header.Clone(),
+		Body:             reqBody,
+		TransferEncoding: req.TransferEncoding,
+	}
+
+	startTime := time.Now()
+	resp, err := h.do(req, reqBody)
+	if err != nil {
+		result.setTime(startTime)
+		result.TestError = newTestError(ErrorTransport, err.Error())
+		h.addTestResult(result)
+		return
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	result.setTime(startTime)
+
+	if h.DumpResponses {
+		h.dumpResponse(resp, respBody)
+	}
+
+	result.TestResponse = &ApiTestResponseCapture{
+		Status:  resp.StatusCode,
+		Proto:   resp.Proto,
+		Header:  resp.Header.Clone(),
+		Body:    respBody,
+		Trailer: resp.Trailer.Clone(),
+		Url:     resp.Request.URL.String(),
+		Tls:     captureTls(resp.TLS),
+	}
+
+	statusErr := checkStatus(httpReq, resp)
+
+	switch {
+	case err != nil:
+		result.TestError = responseError(ErrorTransport, err.Error(), result.TestResponse)
+	case statusErr != nil:
+		result.TestError = responseError(ErrorStatus, statusErr.Error(), result.TestResponse)
+	default:
+		result.TestStatus = true
+		for _, check := range responseChecks {
+			if err := check(h, httpReq, result.TestResponse); err != nil {
+				result.TestStatus = false
+				result.TestError = responseError(ErrorAssertion, err.Error(), result.TestResponse)
+				break
+			}
+		}
+	}
+
+	h.addTestResult(result)
+}
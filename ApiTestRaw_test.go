@@ -0,0 +1,59 @@
+package gotest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCreateTestRawBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body func() io.Reader
+	}{
+		{name: "body with GetBody", body: func() io.Reader { return strings.NewReader("file content") }},
+		{
+			name: "body without GetBody",
+			body: func() io.Reader { return io.MultiReader(strings.NewReader("file content")) },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var received string
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/files/1", func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				received = string(body)
+				w.WriteHeader(http.StatusNoContent)
+			})
+
+			h := InitApiTestWithHandler(mux)
+			defer h.Close()
+
+			h.Output, h.CaptureOn = io.Discard, CaptureAlways
+
+			req, err := http.NewRequest(http.MethodPut, h.Server.URL+"/files/1", test.body())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			h.CreateTestRaw(req, http.StatusNoContent, "Upload")
+
+			result := h.Result[1]
+			if !result.TestStatus {
+				t.Fatalf("expected the test case to pass, got %s", result.TestError)
+			}
+
+			if received != "file content" {
+				t.Errorf("expected the server to receive the body, got %q", received)
+			}
+
+			if got := string(result.TestRequest.Body); got != "file content" {
+				t.Errorf("expected the body to be recorded, got %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package gotest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// resolveLocation function resolves a location, absolute or relative, against the URL of a response.
+func resolveLocation(baseUrl string, location string) (string, error) {
+	base, err := url.Parse(baseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	reference, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+
+	return base.ResolveReference(reference).String(), nil
+}
+
+// checkExpectedRedirect function checks that the response is a redirect to the ExpectedRedirectLocation of the test
+// case, or to a location matching its ExpectedRedirectPattern.
+func checkExpectedRedirect(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if httpReq.ExpectedRedirectLocation == "" && httpReq.ExpectedRedirectPattern == "" {
+		return nil
+	}
+
+	if resp.Status < 300 || resp.Status > 399 {
+		return fmt.Errorf("redirect: expected a 3xx status code, got %d", resp.Status)
+	}
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return errors.New("redirect: header Location not present")
+	}
+
+	actual, err := resolveLocation(resp.Url, location)
+	if err != nil {
+		return fmt.Errorf("redirect: invalid Location %q: %w", location, err)
+	}
+
+	if httpReq.ExpectedRedirectLocation != "" {
+		expected, err := resolveLocation(resp.Url, httpReq.ExpectedRedirectLocation)
+		if err != nil {
+			return fmt.Errorf("redirect: invalid ExpectedRedirectLocation: %w", err)
+		}
+
+		if actual != expected {
+			return fmt.Errorf("redirect: expected location %s, got %s", expected, actual)
+		}
+	}
+
+	if httpReq.ExpectedRedirectPattern != "" {
+		pattern, err := regexp.Compile(httpReq.ExpectedRedirectPattern)
+		if err != nil {
+			return fmt.Errorf("redirect: invalid ExpectedRedirectPattern: %w", err)
+		}
+
+		if !pattern.MatchString(location) && !pattern.MatchString(actual) {
+			return fmt.Errorf("redirect: location %s does not match %q", actual, httpReq.ExpectedRedirectPattern)
+		}
+	}
+
+	return nil
+}
+
+// redirectChain function returns the URLs of the redirects followed to get a response, in order.
+func redirectChain(resp *http.Response) []string {
+	var chain []string
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		chain = append(chain, req.URL.String())
+	}
+
+	slices.Reverse(chain)
+	return chain
+}
+
+// checkExpectedRedirectChain function checks that the URLs of the followed redirects are the ExpectedRedirectChain
+// of the test case, or match its ExpectedRedirectChainPatterns.
+func checkExpectedRedirectChain(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if httpReq.ExpectedRedirectChain == nil && httpReq.ExpectedRedirectChainPatterns == nil {
+		return nil
+	}
+
+	if httpReq.ExpectedRedirectLocation != "" || httpReq.ExpectedRedirectPattern != "" {
+		return errors.New("redirect chain: cannot be checked with an ExpectedRedirectLocation or " +
+			"ExpectedRedirectPattern, whose redirect is not followed")
+	}
+
+	actual := "[" + strings.Join(resp.Redirects, " -> ") + "]"
+
+	if httpReq.ExpectedRedirectChain != nil {
+		expected := make([]string, 0, len(httpReq.ExpectedRedirectChain))
+		for _, location := range httpReq.ExpectedRedirectChain {
+			resolved, err := resolveLocation(resp.Url, location)
+			if err != nil {
+				return fmt.Errorf("redirect chain: invalid ExpectedRedirectChain: %w", err)
+			}
+
+			expected = append(expected, resolved)
+		}
+
+		if !slices.Equal(expected, resp.Redirects) {
+			return fmt.Errorf("redirect chain: expected [%s], got %s", strings.Join(expected, " -> "), actual)
+		}
+	}
+
+	if httpReq.ExpectedRedirectChainPatterns != nil {
+		if len(resp.Redirects) != len(httpReq.ExpectedRedirectChainPatterns) {
+			return fmt.Errorf("redirect chain: expected %d redirects, got %s",
+				len(httpReq.ExpectedRedirectChainPatterns), actual)
+		}
+
+		for i, expression := range httpReq.ExpectedRedirectChainPatterns {
+			pattern, err := regexp.Compile(expression)
+			if err != nil {
+				return fmt.Errorf("redirect chain: invalid ExpectedRedirectChainPatterns: %w", err)
+			}
+
+			if !pattern.MatchString(resp.Redirects[i]) {
+				return fmt.Errorf("redirect chain: redirect %d to %s does not match %q, got %s", i+1,
+					resp.Redirects[i], expression, actual)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package gotest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestExpectedRedirectChain(t *testing.T) {
+	tests := []struct {
+		name      string
+		chain     []string
+		patterns  []string
+		wantError string
+	}{
+		{name: "chain", chain: []string{"/login", "/home"}},
+		{name: "patterns", patterns: []string{`/login$`, `/home$`}},
+		{name: "wrong chain", chain: []string{"/home"}, wantError: "redirect chain: expected ["},
+		{name: "wrong count of patterns", patterns: []string{`/home$`}, wantError: "expected 1 redirects"},
+		{
+			name:      "pattern not matched",
+			patterns:  []string{`/home$`, `/login$`},
+			wantError: `redirect 1 to`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.Handle("/start", http.RedirectHandler("/login", http.StatusFound))
+			mux.Handle("/login", http.RedirectHandler("/home", http.StatusMovedPermanently))
+			mux.HandleFunc("/home", func(w http.ResponseWriter, r *http.Request) {})
+
+			h := InitApiTestWithHandler(mux)
+			defer h.Close()
+
+			h.Output = io.Discard
+			h.CreateTest(ApiTestRequest{Details: "Start", ApiUrl: "/start", ApiMethod: http.MethodGet,
+				ExpectedStatus: http.StatusOK, ExpectedRedirectChain: test.chain,
+				ExpectedRedirectChainPatterns: test.patterns})
+
+			result := h.Result[1]
+			if test.wantError == "" {
+				if !result.TestStatus {
+					t.Errorf("expected the test case to pass, got %s", result.TestError)
+				}
+
+				return
+			}
+
+			if result.TestStatus || !strings.Contains(result.TestError.String(), test.wantError) {
+				t.Errorf("expected the error %q, got %q", test.wantError, result.TestError.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,370 @@
+package gotest
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// htmlReportRow is a row of the HTML report.
+type htmlReportRow struct {
+	Result ApiTestResult
+	Status string
+	Error  string
+}
+
+// htmlReportData is the data rendered by the HTML report template.
+type htmlReportData struct {
+	Title       string
+	Tests       int64
+	PassedTests int64
+	FailedTests int64
+	SoftFailed  int64
+	Rows        []htmlReportRow
+}
+
+// htmlReportTemplate is the template of the self-contained HTML report.
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"headers": formatHeaders,
+	"body":    func(body []byte) string { return string(body) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+.summary span { display: inline-block; margin-right: 2em; font-weight: bold; }
+.total { color: #0b7285; } .passed { color: #2b8a3e; } .failed { color: #c92a2a; } .soft { color: #e67700; }
+table { border-collapse: collapse; width: 100%; margin-top: 1em; }
+th, td { border: 1px solid #dee2e6; padding: .4em .6em; text-align: left; vertical-align: top; }
+th { background: #f1f3f5; cursor: pointer; user-select: none; }
+tr.fail td.status { color: #c92a2a; font-weight: bold; } tr.pass td.status { color: #2b8a3e; }
+tr.soft td.status { color: #e67700; font-weight: bold; }
+details { margin-top: .4em; } summary { cursor: pointer; color: #c92a2a; }
+pre { background: #f8f9fa; padding: .6em; overflow-x: auto; white-space: pre-wrap; word-break: break-all; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="summary">
+<span class="total">Total: {{.Tests}}</span>
+<span class="passed">Passed: {{.PassedTests}}/{{.Tests}}</span>
+<span class="failed">Failed: {{.FailedTests}}/{{.Tests}}</span>
+{{- if .SoftFailed}}
+<span class="soft">Soft failed: {{.SoftFailed}}/{{.Tests}}</span>
+{{- end}}
+</div>
+<table id="results">
+<thead><tr><th>No</th><th>Status</th><th>Started</th><th>Time</th><th>Section</th><th>Description</th></tr></thead>
+<tbody>
+{{- range .Rows}}
+<tr class="{{if .Result.TestStatus}}pass{{else if or .Result.TestSoft .Result.TestNotRun}}soft{{else}}fail{{end}}">
+<td data-sort="{{.Result.TestNumber}}">{{.Result.TestNumber}}</td>
+<td class="status">{{.Status}}</td>
+<td data-sort="{{.Result.TestStartTime.UnixNano}}">{{if not .Result.TestStartTime.IsZero}}{{.Result.TestStartTime.Format "2006-01-02 15:04:05.000"}}{{end}}</td>
+<td data-sort="{{.Result.TestTime.Nanoseconds}}">{{.Result.TestTime}}</td>
+<td>{{.Result.TestSection}}</td>
+<td>{{.Result.TestDescription}}
+{{- if not .Result.TestStatus}}
+<details>
+<summary>Failure details</summary>
+{{- if .Error}}
+<h4>Error</h4>
+<pre>{{.Error}}</pre>
+{{- end}}
+{{- with .Result.TestRequest}}
+<h4>Request</h4>
+<pre>{{.Method}} {{.Url}}
+{{headers .Header}}
+{{body .Body}}</pre>
+{{- end}}
+{{- with .Result.TestResponse}}
+<h4>Response</h4>
+<pre>{{.Status}}
+{{headers .Header}}
+{{body .Body}}</pre>
+{{- end}}
+</details>
+{{- end}}
+</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+<script>
+document.querySelectorAll("#results th").forEach(function (th, column) {
+  var ascending = true;
+  th.addEventListener("click", function () {
+    var body = document.querySelector("#results tbody");
+    var rows = Array.prototype.slice.call(body.rows);
+    rows.sort(function (a, b) {
+      var x = a.cells[column].dataset.sort || a.cells[column].textContent;
+      var y = b.cells[column].dataset.sort || b.cells[column].textContent;
+      var order = (isNaN(x) || isNaN(y)) ? x.localeCompare(y) : x - y;
+      return ascending ? order : -order;
+    });
+    ascending = !ascending;
+    rows.forEach(function (row) { body.appendChild(row); });
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+// formatHeaders function formats headers as one "Name: value" line per header, sorted by name.
+func formatHeaders(header http.Header) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var lines []string
+	for _, name := range names {
+		for _, value := range header[name] {
+			lines = append(lines, name+": "+value)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// WriteHTMLReport function writes the result of the API test cases as a self-contained HTML page to w.
+//
+// Example usage:
+//
+// ```
+// file, _ := os.Create("report.html")
+// defer file.Close()
+// T.WriteHTMLReport(file, "API Test Result")
+// ```
+func (h *ApiTest) WriteHTMLReport(w io.Writer, title string) error {
+	return h.Report(w, ApiTestHtmlReporter{Title: title})
+}
+
+// ApiTestHtmlReporter is the ApiTestReporter of a self-contained HTML page.
+type ApiTestHtmlReporter struct {
+	Title string // Title is the title of the page.
+}
+
+// Report function writes the results as a self-contained HTML page to w.
+func (r ApiTestHtmlReporter) Report(w io.Writer, summary ApiTestSummary, results []ApiTestResult) error {
+	data := htmlReportData{
+		Title:       r.Title,
+		Tests:       summary.Tests,
+		PassedTests: summary.PassedTests,
+		FailedTests: summary.FailedTests,
+		SoftFailed:  summary.SoftFailed,
+	}
+
+	for _, result := range results {
+		data.Rows = append(data.Rows, htmlReportRow{
+			Result: result,
+			Status: resultStatus(result),
+			Error:  result.TestError.String(),
+		})
+	}
+
+	return htmlReportTemplate.Execute(w, data)
+}
+
+// prometheusLabelReplacer escapes label values of the Prometheus text format.
+var prometheusLabelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WritePrometheus function writes the result of the API test cases to w in the Prometheus text exposition format.
+func (h *ApiTest) WritePrometheus(w io.Writer) error {
+	return h.Report(w, ApiTestPrometheusReporter{})
+}
+
+// ApiTestPrometheusReporter is the ApiTestReporter of the Prometheus text exposition format.
+type ApiTestPrometheusReporter struct{}
+
+// Report function writes the summary and the results to w in the Prometheus text exposition format. The total count
+// of test cases is the gotest_tests_total counter, and the counts of the passed, failed and soft failed ones of the
+// run are the gotest_tests_passed, gotest_tests_failed and gotest_tests_soft_failed gauges.
+func (ApiTestPrometheusReporter) Report(w io.Writer, summary ApiTestSummary, results []ApiTestResult) error {
+	var builder strings.Builder
+
+	metrics := []struct {
+		name       string
+		help       string
+		metricType string
+		value      int64
+	}{
+		{"gotest_tests_total", "Total number of API test cases.", "counter", summary.Tests},
+		{"gotest_tests_passed", "Number of passed API test cases.", "gauge", summary.PassedTests},
+		{"gotest_tests_failed", "Number of failed API test cases.", "gauge", summary.FailedTests},
+		{"gotest_tests_soft_failed", "Number of failed soft API test cases.", "gauge", summary.SoftFailed},
+	}
+
+	for _, metric := range metrics {
+		fmt.Fprintf(&builder, "# HELP %s %s\n# TYPE %s %s\n%s %d\n",
+			metric.name, metric.help, metric.name, metric.metricType, metric.name, metric.value)
+	}
+
+	builder.WriteString("# HELP gotest_test_duration_seconds Duration of the API test case.\n")
+	builder.WriteString("# TYPE gotest_test_duration_seconds gauge\n")
+	for _, result := range results {
+		fmt.Fprintf(&builder, "gotest_test_duration_seconds{%s} %g\n", prometheusLabels(result),
+			result.TestTime.Seconds())
+	}
+
+	builder.WriteString("# HELP gotest_test_passed Whether the API test case passed (1) or failed (0).\n")
+	builder.WriteString("# TYPE gotest_test_passed gauge\n")
+	for _, result := range results {
+		passed := 0
+		if result.TestStatus {
+			passed = 1
+		}
+
+		fmt.Fprintf(&builder, "gotest_test_passed{%s} %d\n", prometheusLabels(result), passed)
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// prometheusLabels function returns the Prometheus labels identifying a test case.
+func prometheusLabels(result ApiTestResult) string {
+	return fmt.Sprintf(`number="%d",name="%s",description="%s"`, result.TestNumber,
+		prometheusLabelReplacer.Replace(result.TestName), prometheusLabelReplacer.Replace(result.TestDescription))
+}
+
+// ApiTestCsvReporter is the ApiTestReporter of a CSV document, with a header row and a row per test case.
+type ApiTestCsvReporter struct{}
+
+// Report function writes the results as a CSV document to w.
+func (ApiTestCsvReporter) Report(w io.Writer, _ ApiTestSummary, results []ApiTestResult) error {
+	writer := csv.NewWriter(w)
+	records := [][]string{{"number", "name", "description", "section", "status", "time_seconds", "retries", "error"}}
+
+	for _, result := range results {
+		records = append(records, []string{
+			strconv.FormatInt(result.TestNumber, 10),
+			result.TestName,
+			result.TestDescription,
+			result.TestSection,
+			resultStatus(result),
+			strconv.FormatFloat(result.TestTime.Seconds(), 'f', -1, 64),
+			strconv.Itoa(result.TestRetries),
+			result.TestError.String(),
+		})
+	}
+
+	return writer.WriteAll(records)
+}
+
+// markdownCellReplacer escapes the cells of a Markdown table.
+var markdownCellReplacer = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+// ApiTestMarkdownReporter is the ApiTestReporter of a Markdown document, like for a pull request comment.
+type ApiTestMarkdownReporter struct {
+	Title string // Title is the title of the document, if any.
+}
+
+// Report function writes the summary and the results as a Markdown table to w.
+func (r ApiTestMarkdownReporter) Report(w io.Writer, summary ApiTestSummary, results []ApiTestResult) error {
+	var builder strings.Builder
+	if r.Title != "" {
+		fmt.Fprintf(&builder, "# %s\n\n", r.Title)
+	}
+
+	fmt.Fprintf(&builder, "**Total:** %d, **Passed:** %d, **Failed:** %d", summary.Tests, summary.PassedTests,
+		summary.FailedTests)
+	if summary.SoftFailed > 0 {
+		fmt.Fprintf(&builder, ", **Soft failed:** %d", summary.SoftFailed)
+	}
+
+	if summary.NotRunTests > 0 {
+		fmt.Fprintf(&builder, ", **Not run:** %d", summary.NotRunTests)
+	}
+
+	builder.WriteString("\n\n| No | Status | Time | Section | Description | Error |\n" +
+		"| --- | --- | --- | --- | --- | --- |\n")
+	for _, result := range results {
+		fmt.Fprintf(&builder, "| %d | %s | %s | %s | %s | %s |\n", result.TestNumber, resultStatus(result),
+			result.TestTime, markdownCellReplacer.Replace(result.TestSection),
+			markdownCellReplacer.Replace(result.TestDescription),
+			markdownCellReplacer.Replace(result.TestError.String()))
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// reportFileTitle is the title of the reports of WriteReportFile.
+const reportFileTitle = "API Test Result"
+
+// reportFormats is the reporters of the formats of WriteReportFile.
+var reportFormats = map[string]ApiTestReporter{
+	"json":     ApiTestJsonReporter{},
+	"junit":    ApiTestJunitReporter{Name: reportFileTitle},
+	"csv":      ApiTestCsvReporter{},
+	"markdown": ApiTestMarkdownReporter{Title: reportFileTitle},
+	"html":     ApiTestHtmlReporter{Title: reportFileTitle},
+}
+
+// reportExtensions is the formats of WriteReportFile inferred from the file extensions.
+var reportExtensions = map[string]string{
+	".json":     "json",
+	".xml":      "junit",
+	".csv":      "csv",
+	".md":       "markdown",
+	".markdown": "markdown",
+	".html":     "html",
+	".htm":      "html",
+}
+
+// WriteReportFile function writes the result of the API test cases to the file at path, created or truncated, in
+// format, one of json, junit, csv, markdown and html. Without format, it is inferred from the extension of path,
+// like junit for .xml and markdown for .md.
+//
+// Example usage:
+//
+// ```
+// if err := T.WriteReportFile("artifacts/report.xml", ""); err != nil {
+// log.Fatal(err)
+// }
+// ```
+func (h *ApiTest) WriteReportFile(path string, format string) error {
+	if format == "" {
+		format = reportExtensions[strings.ToLower(filepath.Ext(path))]
+		if format == "" {
+			return fmt.Errorf("cannot infer the report format of %s", path)
+		}
+	}
+
+	reporter, isPresent := reportFormats[strings.ToLower(format)]
+	if !isPresent {
+		return fmt.Errorf("unknown report format %q", format)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	if err := h.Report(writer, reporter); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,95 @@
+package gotest
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusReporter(t *testing.T) {
+	summary := ApiTestSummary{Tests: 3, PassedTests: 1, FailedTests: 2, SoftFailed: 1}
+	results := []ApiTestResult{
+		{TestNumber: 1, TestDescription: `List "users"`, TestStatus: true, TestTime: 250 * time.Millisecond},
+		{TestNumber: 2, TestDescription: "Get user", TestTime: time.Second},
+	}
+
+	var builder strings.Builder
+	if err := (ApiTestPrometheusReporter{}).Report(&builder, summary, results); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		line string
+	}{
+		{name: "total counter", line: "# TYPE gotest_tests_total counter\ngotest_tests_total 3\n"},
+		{name: "passed gauge", line: "# TYPE gotest_tests_passed gauge\ngotest_tests_passed 1\n"},
+		{name: "failed gauge", line: "# TYPE gotest_tests_failed gauge\ngotest_tests_failed 2\n"},
+		{name: "soft failed gauge", line: "# TYPE gotest_tests_soft_failed gauge\ngotest_tests_soft_failed 1\n"},
+		{name: "escaped label", line: `description="List \"users\""`},
+		{name: "duration", line: "} 0.25\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !strings.Contains(builder.String(), test.line) {
+				t.Errorf("expected %q in the report, got:\n%s", test.line, builder.String())
+			}
+		})
+	}
+}
+
+func TestFileReporters(t *testing.T) {
+	summary := ApiTestSummary{Tests: 2, PassedTests: 1, FailedTests: 1, NotRunTests: 1}
+	results := []ApiTestResult{
+		{TestNumber: 1, TestName: "login", TestDescription: "Login", TestSection: "auth", TestStatus: true,
+			TestTime: 250 * time.Millisecond, TestRetries: 1},
+		{TestNumber: 2, TestDescription: "Get | user", TestError: newTestError(ErrorStatus, "unexpected\nstatus")},
+		{TestNumber: 3, TestDescription: "Delete user", TestNotRun: true},
+	}
+
+	tests := []struct {
+		name     string
+		reporter ApiTestReporter
+		lines    []string
+	}{
+		{
+			name:     "csv",
+			reporter: ApiTestCsvReporter{},
+			lines: []string{
+				"number,name,description,section,status,time_seconds,retries,error\n",
+				"1,login,Login,auth,true,0.25,1,\n",
+				"3,,Delete user,,not run,0,0,\n",
+			},
+		},
+		{
+			name:     "markdown",
+			reporter: ApiTestMarkdownReporter{Title: "API"},
+			lines: []string{
+				"# API\n\n**Total:** 2, **Passed:** 1, **Failed:** 1, **Not run:** 1\n",
+				"| 1 | true | 250ms | auth | Login |  |\n",
+				`| 2 | false | 0s |  | Get \| user | unexpected<br>status |`,
+			},
+		},
+		{
+			name:     "html",
+			reporter: ApiTestHtmlReporter{Title: "API"},
+			lines:    []string{"<title>API</title>", "Get | user", "<details>"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var builder strings.Builder
+			if err := test.reporter.Report(&builder, summary, results); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, line := range test.lines {
+				if !strings.Contains(builder.String(), line) {
+					t.Errorf("expected %q in the report, got:\n%s", line, builder.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,485 @@
+package gotest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// ApiTestSummary is the summary of the result of the API test cases.
+type ApiTestSummary struct {
+	Tests       int64         // Tests is the count of total test cases.
+	PassedTests int64         // PassedTests is the count of passed test cases.
+	FailedTests int64         // FailedTests is the count of failed test cases.
+	SoftFailed  int64         // SoftFailed is the count of failed Soft test cases, included in FailedTests.
+	NotRunTests int64         // NotRunTests is the count of test cases not run within MaxSuiteDuration.
+	Time        time.Duration // Time is the total time of the test cases.
+	Duplicates  int64         // Duplicates is the count of test cases sharing the name or details of an earlier one.
+	P95         time.Duration // P95 is the 95th percentile of the times of the test cases.
+	P99         time.Duration // P99 is the 99th percentile of the times of the test cases.
+	MaxP95      time.Duration // MaxP95 is the SuiteMaxP95 of the test cases, if any.
+	MaxP99      time.Duration // MaxP99 is the SuiteMaxP99 of the test cases, if any.
+
+	Connections ApiTestConnectionStats // Connections is the connections of the requests, with TrackConnections.
+
+	// IgnoredFailed is the count of failed test cases with a tag of ExitIgnoreTags, included in SoftFailed.
+	IgnoredFailed int64
+
+	DuplicateRequests int64 // DuplicateRequests is the count of test cases with the request of an earlier one.
+
+	ServerConnectionsPeak int64 // ServerConnectionsPeak is the peak count of concurrent connections of the Server.
+	MaxServerConnections  int   // MaxServerConnections is the MaxServerConnections of the test cases, if any.
+}
+
+// ApiTestReporter writes the result of the API test cases in a report format, like a table, JSON or JUnit XML.
+type ApiTestReporter interface {
+	Report(w io.Writer, summary ApiTestSummary, results []ApiTestResult) error // Report writes the report to w.
+}
+
+// Summary function returns the summary of the result of the API test cases. The percentiles of the times are of the
+// test cases that sent a request only, not of the ones not run or failed before sending it, like on a setup error.
+func (h *ApiTest) Summary() ApiTestSummary {
+	summary := ApiTestSummary{
+		Tests:       h.Tests,
+		PassedTests: h.PassedTests,
+		FailedTests: h.FailedTests,
+		SoftFailed:  h.SoftFailed,
+		NotRunTests: h.NotRunTests,
+		MaxP95:      h.SuiteMaxP95,
+		MaxP99:      h.SuiteMaxP99,
+		Connections: h.ConnectionStats(),
+
+		ServerConnectionsPeak: h.ServerConnectionsPeak(),
+		MaxServerConnections:  h.MaxServerConnections,
+	}
+
+	times := make([]time.Duration, 0, len(h.Result))
+	for _, result := range h.Result {
+		summary.Time += result.TestTime
+		if !result.TestNotRun && !result.TestStartTime.IsZero() {
+			times = append(times, result.TestTime)
+		}
+
+		if result.TestDuplicateOf != 0 {
+			summary.Duplicates++
+		}
+
+		if result.TestDuplicateRequestOf != 0 {
+			summary.DuplicateRequests++
+		}
+
+		if result.TestIgnored && !result.TestStatus && !result.TestNotRun {
+			summary.IgnoredFailed++
+		}
+	}
+
+	slices.Sort(times)
+	summary.P95, summary.P99 = percentile(times, 95), percentile(times, 99)
+
+	return summary
+}
+
+// percentile function returns the nearest-rank percentile of sorted times, or 0 without times.
+func percentile(times []time.Duration, percent int) time.Duration {
+	if len(times) == 0 {
+		return 0
+	}
+
+	rank := (len(times)*percent + 99) / 100
+	return times[max(rank, 1)-1]
+}
+
+// latencyError function returns an error when the 95th or 99th percentile of the times exceeds its maximum.
+func (s ApiTestSummary) latencyError() error {
+	if s.MaxP95 > 0 && s.P95 > s.MaxP95 {
+		return fmt.Errorf("p95 of %s exceeds the maximum of %s", s.P95, s.MaxP95)
+	}
+
+	if s.MaxP99 > 0 && s.P99 > s.MaxP99 {
+		return fmt.Errorf("p99 of %s exceeds the maximum of %s", s.P99, s.MaxP99)
+	}
+
+	return nil
+}
+
+// suiteError function returns an error when the suite exceeds its maximum percentiles of the times or its maximum
+// count of concurrent connections of the Server.
+func (s ApiTestSummary) suiteError() error {
+	if err := s.latencyError(); err != nil {
+		return err
+	}
+
+	if s.MaxServerConnections > 0 && s.ServerConnectionsPeak > int64(s.MaxServerConnections) {
+		return fmt.Errorf("peak of %d concurrent server connections exceeds the maximum of %d",
+			s.ServerConnectionsPeak, s.MaxServerConnections)
+	}
+
+	return nil
+}
+
+// Results function returns the results of the API test cases, ordered by number.
+func (h *ApiTest) Results() []ApiTestResult {
+	numbers := h.resultNumbers()
+
+	results := make([]ApiTestResult, 0, len(numbers))
+	for _, number := range numbers {
+		results = append(results, h.Result[number])
+	}
+
+	return results
+}
+
+// Report function writes the result of the API test cases to w with every reporter, in order. Use ReportTo to
+// write the reports of several reporters to different writers in one call.
+//
+// Example usage:
+//
+// ```
+// file, _ := os.Create("junit.xml")
+// defer file.Close()
+// T.Report(os.Stdout, ApiTestTableReporter{}, ReportTo(file, ApiTestJunitReporter{Name: "API"}))
+// ```
+func (h *ApiTest) Report(w io.Writer, reporters ...ApiTestReporter) error {
+	summary, results := h.Summary(), h.Results()
+
+	for _, reporter := range reporters {
+		if err := reporter.Report(w, summary, results); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reporterTo is an ApiTestReporter writing to its own writer.
+type reporterTo struct {
+	w        io.Writer       // w is the writer of the report.
+	reporter ApiTestReporter // reporter is the reporter of the report.
+}
+
+// Report function writes the report to the writer of the reporter, instead of w.
+func (r reporterTo) Report(_ io.Writer, summary ApiTestSummary, results []ApiTestResult) error {
+	return r.reporter.Report(r.w, summary, results)
+}
+
+// ReportTo function returns a reporter writing the report of reporter to w, whatever the writer it is given.
+func ReportTo(w io.Writer, reporter ApiTestReporter) ApiTestReporter {
+	return reporterTo{w: w, reporter: reporter}
+}
+
+// ApiTestTableReporter is the ApiTestReporter of the colored terminal table of DumpApiTestResult.
+type ApiTestTableReporter struct{}
+
+// Report function writes the results as a colored table, followed by the summary, to w.
+func (ApiTestTableReporter) Report(w io.Writer, summary ApiTestSummary, results []ApiTestResult) error {
+	fmt.Fprintf(w, "\nAPI Test Result:\n\n")
+	fmt.Fprintf(w, "┌──────┬──────────┬─────────────────┬─────────────────────--------------►\n")
+	fmt.Fprintf(w, "│ %-4s │ %-8s │ %-15s │ %s\n", "No", "Status", "Time", "Description")
+	fmt.Fprintf(w, "├──────┼──────────┼─────────────────┼─────────────────────--------------►\n")
+
+	var section string
+	for _, result := range results {
+		if result.TestSection != section {
+			section = result.TestSection
+			fmt.Fprintf(w, "│ \033[1;36m%s\033[0;0m\n", section)
+		}
+
+		fmt.Fprintf(w, "│ %-4d │ %-8s │ %-15s │ %s", result.TestNumber, resultStatus(result), result.TestTime,
+			result.TestDescription)
+
+		if result.TestError != nil {
+			fmt.Fprint(w, "\u001B[1;31m [ Error:\033[0;0m ", result.TestError.String(), "\u001B[1;31m ]\u001B[0;0m")
+		}
+
+		fmt.Fprintf(w, "\n")
+	}
+
+	fmt.Fprintf(w, "└──────┴──────────┴─────────────────┴─────────────────────--------------►\n")
+
+	fmt.Fprintf(w, "\n%-42s : \033[1;36m%d\033[0;0m\n", "Total white box API test cases", summary.Tests)
+	fmt.Fprintf(w, "%-42s : \033[1;32m%d/%d\033[0;0m\n", "Total passed white box API test cases",
+		summary.PassedTests, summary.Tests)
+	fmt.Fprintf(w, "%-42s : \033[1;31m%d/%d\033[0;0m\n", "Total failed white box API test cases",
+		summary.FailedTests, summary.Tests)
+
+	if summary.SoftFailed > 0 {
+		fmt.Fprintf(w, "%-42s : \033[1;33m%d/%d\033[0;0m\n", "Total soft failed white box API test cases",
+			summary.SoftFailed, summary.Tests)
+	}
+
+	if summary.IgnoredFailed > 0 {
+		fmt.Fprintf(w, "%-42s : \033[1;33m%d/%d\033[0;0m\n", "Total ignored white box API test cases",
+			summary.IgnoredFailed, summary.Tests)
+	}
+
+	if summary.NotRunTests > 0 {
+		fmt.Fprintf(w, "%-42s : \033[1;33m%d/%d\033[0;0m\n", "Total not run white box API test cases",
+			summary.NotRunTests, summary.Tests)
+	}
+
+	if summary.Duplicates > 0 {
+		fmt.Fprintf(w, "%-42s : \033[1;33m%d/%d\033[0;0m\n", "Total duplicate white box API test cases",
+			summary.Duplicates, summary.Tests)
+	}
+
+	if summary.DuplicateRequests > 0 {
+		fmt.Fprintf(w, "\033[1;33m%d duplicate requests detected\033[0;0m\n", summary.DuplicateRequests)
+	}
+
+	if summary.MaxP95 > 0 {
+		fmt.Fprintf(w, "%-42s : %s\n", "P95 time of white box API test cases", summary.P95)
+	}
+
+	if summary.MaxP99 > 0 {
+		fmt.Fprintf(w, "%-42s : %s\n", "P99 time of white box API test cases", summary.P99)
+	}
+
+	if connections := summary.Connections; connections.New+connections.Reused > 0 {
+		fmt.Fprintf(w, "%-42s : %d new, %d reused (%d idle)\n", "Connections of white box API test cases",
+			connections.New, connections.Reused, connections.Idle)
+	}
+
+	if summary.MaxServerConnections > 0 {
+		fmt.Fprintf(w, "%-42s : %d\n", "Peak connections of the server", summary.ServerConnectionsPeak)
+	}
+
+	if err := summary.suiteError(); err != nil {
+		fmt.Fprint(w, "\u001B[1;31m[ Error:\033[0;0m ", err, "\u001B[1;31m ]\u001B[0;0m\n")
+	}
+
+	_, err := fmt.Fprintf(w, "\n")
+	return err
+}
+
+// resultStatus function returns the status of a result in reports, "ignored" for a failed test case with a tag of
+// ExitIgnoreTags, "soft" for a failed Soft test case and "not run" for a test case not run within MaxSuiteDuration.
+func resultStatus(result ApiTestResult) string {
+	if result.TestNotRun {
+		return "not run"
+	}
+
+	if !result.TestStatus && result.TestIgnored {
+		return "ignored"
+	}
+
+	if !result.TestStatus && result.TestSoft {
+		return "soft"
+	}
+
+	return strconv.FormatBool(result.TestStatus)
+}
+
+// jsonReportResult is a result of the JSON report.
+type jsonReportResult struct {
+	Number      int64      `json:"number"`
+	Name        string     `json:"name,omitempty"`
+	Description string     `json:"description"`
+	Section     string     `json:"section,omitempty"`
+	Status      bool       `json:"status"`
+	Soft        bool       `json:"soft,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	TimeSeconds float64    `json:"time_seconds"`
+	StartTime   *time.Time `json:"start_time,omitempty"`
+	EndTime     *time.Time `json:"end_time,omitempty"`
+	Retries     int        `json:"retries,omitempty"`
+	DuplicateOf int64      `json:"duplicate_of,omitempty"`
+
+	ErrorDetails *ApiTestError `json:"error_details,omitempty"`
+	Tags         []string      `json:"tags,omitempty"`
+	Ignored      bool          `json:"ignored,omitempty"`
+
+	DuplicateRequestOf int64 `json:"duplicate_request_of,omitempty"`
+
+	Request  *jsonReportCapture `json:"request,omitempty"`
+	Response *jsonReportCapture `json:"response,omitempty"`
+}
+
+// jsonReportCapture is the captured request or response of a result of the JSON report, retained per CaptureOn.
+type jsonReportCapture struct {
+	Method string      `json:"method,omitempty"`
+	Url    string      `json:"url,omitempty"`
+	Status int         `json:"status,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// reportTime function returns a time of the JSON report, nil for the zero time of a test case that did not run.
+func reportTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// jsonRequestCapture function returns the captured request of the JSON report, nil without a capture.
+func jsonRequestCapture(request *ApiTestRequestCapture) *jsonReportCapture {
+	if request == nil {
+		return nil
+	}
+
+	return &jsonReportCapture{Method: request.Method, Url: request.Url, Header: request.Header,
+		Body: string(request.Body)}
+}
+
+// jsonResponseCapture function returns the captured response of the JSON report, nil without a capture.
+func jsonResponseCapture(response *ApiTestResponseCapture) *jsonReportCapture {
+	if response == nil {
+		return nil
+	}
+
+	return &jsonReportCapture{Url: response.Url, Status: response.Status, Header: response.Header,
+		Body: string(response.Body)}
+}
+
+// jsonReport is the JSON report.
+type jsonReport struct {
+	Tests             int64              `json:"tests"`
+	PassedTests       int64              `json:"passed_tests"`
+	FailedTests       int64              `json:"failed_tests"`
+	SoftFailed        int64              `json:"soft_failed_tests,omitempty"`
+	IgnoredFailed     int64              `json:"ignored_failed_tests,omitempty"`
+	NotRunTests       int64              `json:"not_run_tests,omitempty"`
+	TimeSeconds       float64            `json:"time_seconds"`
+	Duplicates        int64              `json:"duplicates,omitempty"`
+	DuplicateRequests int64              `json:"duplicate_requests,omitempty"`
+	Results           []jsonReportResult `json:"results"`
+
+	ServerConnectionsPeak int64 `json:"server_connections_peak,omitempty"`
+}
+
+// ApiTestJsonReporter is the ApiTestReporter of a JSON document.
+type ApiTestJsonReporter struct{}
+
+// Report function writes the summary and the results, with their captures retained per CaptureOn, as an indented JSON
+// document to w.
+func (ApiTestJsonReporter) Report(w io.Writer, summary ApiTestSummary, results []ApiTestResult) error {
+	report := jsonReport{
+		Tests:             summary.Tests,
+		PassedTests:       summary.PassedTests,
+		FailedTests:       summary.FailedTests,
+		SoftFailed:        summary.SoftFailed,
+		IgnoredFailed:     summary.IgnoredFailed,
+		NotRunTests:       summary.NotRunTests,
+		TimeSeconds:       summary.Time.Seconds(),
+		Duplicates:        summary.Duplicates,
+		DuplicateRequests: summary.DuplicateRequests,
+		Results:           make([]jsonReportResult, 0, len(results)),
+
+		ServerConnectionsPeak: summary.ServerConnectionsPeak,
+	}
+
+	for _, result := range results {
+		report.Results = append(report.Results, jsonReportResult{
+			Number:      result.TestNumber,
+			Name:        result.TestName,
+			Description: result.TestDescription,
+			Section:     result.TestSection,
+			Status:      result.TestStatus,
+			Soft:        result.TestSoft,
+			Error:       result.TestError.String(),
+			TimeSeconds: result.TestTime.Seconds(),
+			StartTime:   reportTime(result.TestStartTime),
+			EndTime:     reportTime(result.TestEndTime),
+			Retries:     result.TestRetries,
+			DuplicateOf: result.TestDuplicateOf,
+
+			ErrorDetails: result.TestError,
+			Tags:         result.TestTags,
+			Ignored:      result.TestIgnored,
+
+			DuplicateRequestOf: result.TestDuplicateRequestOf,
+
+			Request:  jsonRequestCapture(result.TestRequest),
+			Response: jsonResponseCapture(result.TestResponse),
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(report)
+}
+
+// junitTestSuite is the test suite of the JUnit XML report.
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int64           `xml:"tests,attr"`
+	Failures  int64           `xml:"failures,attr"`
+	Skipped   int64           `xml:"skipped,attr,omitempty"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a test case of the JUnit XML report.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr,omitempty"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitFailure `xml:"skipped,omitempty"`
+}
+
+// junitFailure is the failure, or the skipping, of a test case of the JUnit XML report.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+// ApiTestJunitReporter is the ApiTestReporter of the JUnit XML format, read by most CI servers.
+type ApiTestJunitReporter struct {
+	Name string // Name is the name of the test suite.
+}
+
+// Report function writes the results as a JUnit XML test suite to w. The test cases are named after their name, or
+// else description, and classed by section. The failed Soft test cases are reported as skipped.
+func (r ApiTestJunitReporter) Report(w io.Writer, summary ApiTestSummary, results []ApiTestResult) error {
+	suite := junitTestSuite{
+		Name:     r.Name,
+		Tests:    summary.Tests,
+		Failures: summary.FailedTests - summary.SoftFailed,
+		Skipped:  summary.SoftFailed + summary.NotRunTests,
+		Time:     junitSeconds(summary.Time),
+	}
+
+	for _, result := range results {
+		testCase := junitTestCase{
+			Name:      testKey(result.TestName, result.TestDescription),
+			ClassName: result.TestSection,
+			Time:      junitSeconds(result.TestTime),
+		}
+
+		if result.TestNotRun {
+			testCase.Skipped = &junitFailure{Message: result.TestError.String()}
+		} else if !result.TestStatus && result.TestSoft {
+			testCase.Skipped = &junitFailure{Message: "soft failure: " + result.TestError.String()}
+		} else if !result.TestStatus {
+			testCase.Failure = &junitFailure{Message: result.TestError.String()}
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitSeconds function formats a duration as the seconds of the JUnit XML format.
+func junitSeconds(duration time.Duration) string {
+	return strconv.FormatFloat(duration.Seconds(), 'f', 3, 64)
+}
@@ -0,0 +1,149 @@
+package gotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSummaryPercentilesOfSentRequests(t *testing.T) {
+	startTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	unsent := []ApiTestResult{
+		{TestStartTime: startTime, TestTime: 100 * time.Millisecond},
+		{TestError: newTestError(ErrorSetup, "invalid count of requests 0")},
+		{TestError: newTestError(ErrorSetup, "cannot fetch token")},
+	}
+	for i := 0; i < 20; i++ {
+		unsent = append(unsent, ApiTestResult{TestNotRun: true})
+	}
+
+	tests := []struct {
+		name    string
+		results []ApiTestResult
+		wantP95 time.Duration
+	}{
+		{
+			name:    "sent requests",
+			results: []ApiTestResult{{TestStartTime: startTime, TestTime: 100 * time.Millisecond}},
+			wantP95: 100 * time.Millisecond,
+		},
+		{
+			name:    "not run and setup results ignored",
+			results: unsent,
+			wantP95: 100 * time.Millisecond,
+		},
+		{
+			name:    "no sent request",
+			results: []ApiTestResult{{TestNotRun: true}},
+			wantP95: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := &ApiTest{Result: make(map[int64]ApiTestResult), SuiteMaxP95: 50 * time.Millisecond}
+			for i, result := range test.results {
+				h.Result[int64(i+1)] = result
+			}
+
+			summary := h.Summary()
+			if summary.P95 != test.wantP95 {
+				t.Errorf("expected a p95 of %s, got %s", test.wantP95, summary.P95)
+			}
+
+			if gotErr := summary.latencyError() != nil; gotErr != (test.wantP95 > h.SuiteMaxP95) {
+				t.Errorf("expected a latency error %t, got %t", test.wantP95 > h.SuiteMaxP95, gotErr)
+			}
+		})
+	}
+}
+
+func TestJsonReporterTimes(t *testing.T) {
+	startTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := startTime.Add(150 * time.Millisecond)
+
+	tests := []struct {
+		name      string
+		result    ApiTestResult
+		wantStart string
+		wantEnd   string
+	}{
+		{
+			name:      "run",
+			result:    ApiTestResult{TestStartTime: startTime, TestEndTime: endTime, TestTime: 100 * time.Millisecond},
+			wantStart: "2024-01-01T00:00:00Z",
+			wantEnd:   "2024-01-01T00:00:00.15Z",
+		},
+		{
+			name:   "not run",
+			result: ApiTestResult{TestNotRun: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := (ApiTestJsonReporter{}).Report(&buffer, ApiTestSummary{}, []ApiTestResult{test.result}); err != nil {
+				t.Fatal(err)
+			}
+
+			var report struct {
+				Results []map[string]interface{} `json:"results"`
+			}
+			if err := json.Unmarshal(buffer.Bytes(), &report); err != nil {
+				t.Fatal(err)
+			}
+
+			for key, want := range map[string]string{"start_time": test.wantStart, "end_time": test.wantEnd} {
+				got, isPresent := report.Results[0][key]
+				if want == "" && isPresent {
+					t.Errorf("expected no %s, got %v", key, got)
+				} else if want != "" && got != want {
+					t.Errorf("expected a %s of %s, got %v", key, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestJunitReporter(t *testing.T) {
+	summary := ApiTestSummary{Tests: 4, PassedTests: 1, FailedTests: 2, SoftFailed: 1, NotRunTests: 1,
+		Time: 1500 * time.Millisecond}
+	results := []ApiTestResult{
+		{TestNumber: 1, TestName: "login", TestDescription: "Login", TestSection: "auth", TestStatus: true,
+			TestTime: 250 * time.Millisecond},
+		{TestNumber: 2, TestDescription: "Get user",
+			TestError: newTestError(ErrorStatus, "unexpected status code 404")},
+		{TestNumber: 3, TestDescription: "List users", TestSoft: true,
+			TestError: newTestError(ErrorAssertion, "slow")},
+		{TestNumber: 4, TestDescription: "Delete user", TestNotRun: true,
+			TestError: newTestError(ErrorNotRun, "timeout")},
+	}
+
+	var builder strings.Builder
+	if err := (ApiTestJunitReporter{Name: "API"}).Report(&builder, summary, results); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		line string
+	}{
+		{name: "suite", line: `<testsuite name="API" tests="4" failures="1" skipped="2" time="1.500">`},
+		{name: "passed", line: `<testcase name="login" classname="auth" time="0.250"></testcase>`},
+		{name: "failed", line: `<failure message="unexpected status code 404">`},
+		{name: "soft failed", line: `<skipped message="soft failure: slow">`},
+		{name: "not run", line: "<testcase name=\"Delete user\" time=\"0.000\">\n    <skipped message=\"timeout\">"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !strings.Contains(builder.String(), test.line) {
+				t.Errorf("expected %q in the report, got:\n%s", test.line, builder.String())
+			}
+		})
+	}
+}
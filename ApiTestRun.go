@@ -0,0 +1,321 @@
+package gotest
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Run function creates the test cases of the requests, in order. The TestIndex of each result is the 1-based position
+// of its request, so the results can be matched with the requests whatever the order they are recorded in. With
+// OAuth2, the token is fetched first, and a failure to fetch it is recorded as a failed test case instead of running
+// the requests.
+//
+// Example usage:
+//
+// ```
+// T.Run([]ApiTestRequest{
+// {Details: "List users", ApiUrl: "/users", ApiMethod: http.MethodGet, ExpectedStatus: http.StatusOK},
+// {Details: "Get user", ApiUrl: "/users/1", ApiMethod: http.MethodGet, ExpectedStatus: http.StatusOK},
+// })
+// ```
+func (h *ApiTest) Run(requests []ApiTestRequest) {
+	if h.OAuth2 != nil {
+		if _, err := h.providedToken(false); err != nil {
+			testError := newTestError(ErrorSetup, err.Error())
+			h.addTestResult(ApiTestResult{TestDescription: "Fetch OAuth2 token", TestError: testError})
+			return
+		}
+	}
+
+	if h.suiteStart.IsZero() {
+		h.suiteStart = time.Now()
+	}
+
+	for i, httpReq := range requests {
+		if h.MaxSuiteDuration > 0 && time.Since(h.suiteStart) > h.MaxSuiteDuration {
+			h.addTestResult(ApiTestResult{TestName: httpReq.Name, TestDescription: httpReq.Details, TestIndex: i + 1,
+				TestSoft: httpReq.Soft, TestTags: httpReq.Tags, TestNotRun: true,
+				TestError: newTestError(ErrorNotRun, "not run (time budget exceeded)")})
+			continue
+		}
+
+		h.createTest(httpReq, i+1)
+	}
+}
+
+// CreateEventuallyTest function creates a new test case for an API call of an eventually consistent system, like
+// after an asynchronous write. The request is sent every poll until the test case passes all its checks, or fails
+// when within has elapsed. The count of polls and the time to pass are recorded in the result.
+//
+// Example usage:
+//
+// ```
+// T.CreateEventuallyTest(ApiTestRequest{
+// Details: "Order is shipped", ApiUrl: "/orders/1", ApiMethod: http.MethodGet,
+// ExpectedJsonFields: map[string]interface{}{"status": "shipped"},
+// }, 10*time.Second, 500*time.Millisecond)
+// ```
+func (h *ApiTest) CreateEventuallyTest(httpReq ApiTestRequest, within time.Duration, poll time.Duration) {
+	startTime := time.Now()
+
+	var result ApiTestResult
+	for polls := 1; ; polls++ {
+		result = h.runTest(httpReq)
+		result.TestPolls = polls
+
+		if result.TestStatus {
+			result.TestConsistentAfter = time.Since(startTime)
+			break
+		}
+
+		if time.Since(startTime)+poll > within {
+			result.TestError = result.TestError.wrap(fmt.Sprintf("not passed within %s after %d polls", within,
+				polls))
+			break
+		}
+
+		time.Sleep(poll)
+	}
+
+	result.request, result.baseUrl = &httpReq, h.BaseUrl
+	h.addTestResult(result)
+}
+
+// RunFlow function creates a single test case of a flow, like a user journey, whose steps are run in order, chained
+// by the variables they capture. The flow stops at the first failed step, and passes only when every step passes.
+// The results of the steps run are recorded in TestSteps, and the error names the failed step.
+//
+// Example usage:
+//
+// ```
+// T.RunFlow("Sign up and order", []ApiTestRequest{
+// {Details: "Register", ApiUrl: "/users", ApiMethod: http.MethodPost, ReqBody: user, ExpectedStatus: http.StatusCreated},
+// {Details: "Login", ApiUrl: "/login", ApiMethod: http.MethodPost, ReqBody: credentials,
+// CaptureJson: map[string]string{"token": "token"}},
+// {Details: "Order", ApiUrl: "/orders", ApiMethod: http.MethodPost, BearerToken: "{{token}}", ReqBody: order},
+// })
+// ```
+func (h *ApiTest) RunFlow(name string, steps []ApiTestRequest) {
+	failed := func(i int) string {
+		return fmt.Sprintf("step %d (%s) failed", i+1, testKey(steps[i].Name, steps[i].Details))
+	}
+
+	h.runSteps(ApiTestRequest{Name: name, Details: name}, steps, failed, nil)
+}
+
+// runSteps function creates a single test case, with the name, details, Soft and tags of test, whose steps are run
+// in order. Every step run is recorded in TestSteps, and the test case has the request and response of the last one
+// and the retries of all. The run stops at the first failed step, whose error is wrapped with the prefix of failed,
+// or at the first error of check, if any, called after each passed step with the result whose last step it is.
+func (h *ApiTest) runSteps(test ApiTestRequest, steps []ApiTestRequest, failed func(i int) string,
+	check func(i int, result *ApiTestResult) *ApiTestError) {
+	result := ApiTestResult{TestName: test.Name, TestDescription: test.Details, TestSoft: test.Soft,
+		TestTags: test.Tags}
+	startTime := time.Now()
+
+	for i := range steps {
+		stepResult := h.runTest(steps[i])
+		h.redactResult(&stepResult)
+
+		result.TestSteps = append(result.TestSteps, stepResult)
+		result.TestRequest, result.TestResponse = stepResult.TestRequest, stepResult.TestResponse
+		result.TestRetries += stepResult.TestRetries
+
+		if !stepResult.TestStatus {
+			result.TestError = stepResult.TestError.wrap(failed(i))
+			break
+		}
+
+		if check != nil {
+			if result.TestError = check(i, &result); result.TestError != nil {
+				break
+			}
+		}
+	}
+
+	result.TestStatus = result.TestError == nil
+	result.setTime(startTime)
+	h.addTestResult(result)
+}
+
+// RunByName function creates the test cases of the requests whose Name is one of names, in the order of the
+// requests.
+//
+// Example usage:
+//
+// ```
+// T.RunByName(requests, []string{"create-user", "delete-user"})
+// ```
+func (h *ApiTest) RunByName(requests []ApiTestRequest, names []string) {
+	for i, httpReq := range requests {
+		if slices.Contains(names, httpReq.Name) {
+			h.createTest(httpReq, i+1)
+		}
+	}
+}
+
+// RerunFailed function runs again the failed test cases, and replaces their results in place, keeping their numbers
+// and sections.
+//
+// Example usage:
+//
+// ```
+// T.Run(requests)
+// T.RerunFailed()
+// T.DumpApiTestResult(true)
+// ```
+func (h *ApiTest) RerunFailed() {
+	previousBaseUrl := h.BaseUrl
+	defer func() { h.BaseUrl = previousBaseUrl }()
+
+	for _, number := range h.resultNumbers() {
+		previous := h.Result[number]
+		if previous.TestStatus || previous.request == nil {
+			continue
+		}
+
+		h.BaseUrl = previous.baseUrl
+
+		result := h.runTest(*previous.request)
+		result.request, result.baseUrl = previous.request, previous.baseUrl
+		result.TestNumber, result.TestSection, result.TestIndex = number, previous.TestSection, previous.TestIndex
+		h.redactResult(&result)
+		h.ignoreTags(&result)
+
+		if result.TestStatus {
+			h.PassedTests++
+			h.FailedTests--
+
+			if previous.TestSoft {
+				h.SoftFailed--
+			}
+		}
+
+		h.notifyResult(result)
+
+		h.retainCapture(&result)
+		h.releaseBodies(number)
+		h.retainBodies(&result)
+		h.Result[number] = result
+	}
+}
+
+// AssertFaster function creates a test case comparing the times of two test cases already run, by name, which passes
+// when the test case faster is faster than the test case slower by at least margin, like a cached route against its
+// uncached one. The latest result of each name is compared, and both times are reported.
+//
+// Example usage:
+//
+// ```
+// T.Run(requests)
+// T.AssertFaster("get-user-cached", "get-user", 5*time.Millisecond)
+// ```
+func (h *ApiTest) AssertFaster(faster string, slower string, margin time.Duration) {
+	result := ApiTestResult{TestDescription: fmt.Sprintf("%s faster than %s by %s", faster, slower, margin)}
+
+	fasterResult, isFasterPresent := h.latestResult(faster)
+	slowerResult, isSlowerPresent := h.latestResult(slower)
+
+	switch {
+	case !isFasterPresent:
+		result.TestError = newTestError(ErrorSetup, fmt.Sprintf("no test case named %q", faster))
+	case !isSlowerPresent:
+		result.TestError = newTestError(ErrorSetup, fmt.Sprintf("no test case named %q", slower))
+	case fasterResult.TestTime+margin > slowerResult.TestTime:
+		result.TestError = newTestError(ErrorAssertion, fmt.Sprintf("%s took %s, %s took %s", faster,
+			fasterResult.TestTime, slower, slowerResult.TestTime))
+	default:
+		result.TestStatus = true
+	}
+
+	h.addTestResult(result)
+}
+
+// latestResult function returns the latest result of the test cases named name.
+func (h *ApiTest) latestResult(name string) (ApiTestResult, bool) {
+	numbers := h.resultNumbers()
+	for i := len(numbers) - 1; i >= 0; i-- {
+		if result := h.Result[numbers[i]]; result.TestName == name {
+			return result, true
+		}
+	}
+
+	return ApiTestResult{}, false
+}
+
+// ApiTestMatrix is the results of the same test cases run against several base URLs, by base URL.
+type ApiTestMatrix map[string][]ApiTestResult
+
+// RunAcross function runs the requests against every base URL, like the dev, staging and production environments,
+// and returns the results by base URL. The results are also recorded in a section named after each base URL.
+//
+// Example usage:
+//
+// ```
+// matrix := T.RunAcross([]string{"https://dev.example.com", "https://staging.example.com"}, requests)
+// matrix.Write(os.Stdout)
+// ```
+func (h *ApiTest) RunAcross(baseUrls []string, requests []ApiTestRequest) ApiTestMatrix {
+	previousBaseUrl, previousSection := h.BaseUrl, h.section
+	defer func() {
+		h.BaseUrl = previousBaseUrl
+		h.Section(previousSection)
+	}()
+
+	matrix := make(ApiTestMatrix, len(baseUrls))
+	for _, baseUrl := range baseUrls {
+		h.BaseUrl = baseUrl
+		h.Section(baseUrl)
+
+		first := h.Tests + 1
+		h.Run(requests)
+
+		for number := first; number <= h.Tests; number++ {
+			matrix[baseUrl] = append(matrix[baseUrl], h.Result[number])
+		}
+	}
+
+	return matrix
+}
+
+// Write function writes the matrix to w as a table with a row per test case and a column per base URL.
+func (m ApiTestMatrix) Write(w io.Writer) error {
+	baseUrls := sortedKeys(m)
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%-40s", "Description")
+	for _, baseUrl := range baseUrls {
+		fmt.Fprintf(&builder, " │ %s", baseUrl)
+	}
+
+	builder.WriteString("\n")
+
+	rows := 0
+	for _, results := range m {
+		rows = max(rows, len(results))
+	}
+
+	for row := 0; row < rows; row++ {
+		var description string
+		var statuses []string
+
+		for _, baseUrl := range baseUrls {
+			status := "-"
+			if row < len(m[baseUrl]) {
+				description = m[baseUrl][row].TestDescription
+				status = fmt.Sprint(m[baseUrl][row].TestStatus)
+			}
+
+			statuses = append(statuses, fmt.Sprintf(" │ %-*s", len(baseUrl), status))
+		}
+
+		line := fmt.Sprintf("%-40s%s", description, strings.Join(statuses, ""))
+		builder.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
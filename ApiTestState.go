@@ -0,0 +1,100 @@
+package gotest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// Statuses of the test cases in a state file.
+const (
+	statePassed = "passed"
+	stateFailed = "failed"
+)
+
+// SaveState function writes the status of the test cases to a state file, by name or else description, as a
+// checkpoint of a long suite which can be resumed with ResumeFrom. The test cases passed in the resumed state and
+// skipped since are kept as passed.
+//
+// Example usage:
+//
+// ```
+// T.OnResult = func(ApiTestResult) { T.SaveState("testdata/state.json") }
+// ```
+func (h *ApiTest) SaveState(path string) error {
+	state := make(map[string]string)
+	for key, passed := range h.resumed {
+		if passed {
+			state[key] = statePassed
+		}
+	}
+
+	for _, number := range h.resultNumbers() {
+		result := h.Result[number]
+
+		state[testKey(result.TestName, result.TestDescription)] = stateFailed
+		if result.TestStatus {
+			state[testKey(result.TestName, result.TestDescription)] = statePassed
+		}
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// ResumeFrom function loads a state file written by SaveState, so that the test cases which passed in it are skipped,
+// unless ForceRerun is set. A missing file is an empty state, so the same code starts and resumes the suite.
+//
+// Example usage:
+//
+// ```
+// if err := T.ResumeFrom("testdata/state.json"); err != nil {
+// log.Fatal(err)
+// }
+// T.Run(requests)
+// ```
+func (h *ApiTest) ResumeFrom(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		h.resumed = make(map[string]bool)
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var state map[string]string
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("invalid state %s: %w", path, err)
+	}
+
+	resumed := make(map[string]bool, len(state))
+	for key, status := range state {
+		if status != statePassed && status != stateFailed {
+			return fmt.Errorf("invalid state %s: %q: unknown status %q", path, key, status)
+		}
+
+		resumed[key] = status == statePassed
+	}
+
+	h.resumed = resumed
+	return nil
+}
+
+// skipResumed function reports whether a test case is skipped because it passed in the state of ResumeFrom.
+func (h *ApiTest) skipResumed(httpReq ApiTestRequest) bool {
+	key := testKey(httpReq.Name, httpReq.Details)
+	if h.ForceRerun || !h.resumed[key] {
+		return false
+	}
+
+	h.logger().Info("skipped, passed in the resumed state", "name", httpReq.Name, "description", httpReq.Details)
+	return true
+}
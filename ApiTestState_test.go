@@ -0,0 +1,100 @@
+package gotest
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveStateAndResumeFrom(t *testing.T) {
+	tests := []struct {
+		name         string
+		forceRerun   bool
+		wantRequests map[string]int
+	}{
+		{name: "passed skipped", wantRequests: map[string]int{"/passed": 1, "/failed": 2}},
+		{name: "ForceRerun", forceRerun: true, wantRequests: map[string]int{"/passed": 2, "/failed": 2}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests := make(map[string]int)
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				requests[r.URL.Path]++
+			})
+
+			suite := []ApiTestRequest{
+				{Name: "passed", Details: "Passed", ApiUrl: "/passed", ApiMethod: http.MethodGet,
+					ExpectedStatus: http.StatusOK},
+				{Name: "failed", Details: "Failed", ApiUrl: "/failed", ApiMethod: http.MethodGet,
+					ExpectedStatus: http.StatusCreated},
+			}
+
+			path := filepath.Join(t.TempDir(), "state.json")
+
+			first := InitApiTestWithHandler(mux)
+			defer first.Close()
+
+			first.Output = io.Discard
+			if err := first.ResumeFrom(path); err != nil {
+				t.Fatalf("expected a missing state to be empty, got %s", err)
+			}
+
+			for _, httpReq := range suite {
+				first.CreateTest(httpReq)
+			}
+
+			if err := first.SaveState(path); err != nil {
+				t.Fatal(err)
+			}
+
+			second := InitApiTestWithHandler(mux)
+			defer second.Close()
+
+			second.Output, second.ForceRerun = io.Discard, test.forceRerun
+			if err := second.ResumeFrom(path); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, httpReq := range suite {
+				second.CreateTest(httpReq)
+			}
+
+			for path, want := range test.wantRequests {
+				if requests[path] != want {
+					t.Errorf("expected %d requests to %s, got %d", want, path, requests[path])
+				}
+			}
+		})
+	}
+}
+
+func TestResumeFromInvalidState(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantError string
+	}{
+		{name: "invalid JSON", data: "{", wantError: "invalid state"},
+		{name: "unknown status", data: `{"login": "skipped"}`, wantError: `unknown status "skipped"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "state.json")
+			if err := os.WriteFile(path, []byte(test.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			h := &ApiTest{}
+			if err := h.ResumeFrom(path); err == nil || !strings.Contains(err.Error(), test.wantError) {
+				t.Errorf("expected the error %q, got %v", test.wantError, err)
+			}
+		})
+	}
+}
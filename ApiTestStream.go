@@ -0,0 +1,61 @@
+package gotest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// readChunks function reads a body like io.ReadAll, and returns the times its chunks were read at, since startTime.
+func readChunks(body io.Reader, startTime time.Time) ([]byte, []time.Duration, error) {
+	var data []byte
+	var chunkTimes []time.Duration
+
+	buffer := make([]byte, 32*1024)
+	for {
+		n, err := body.Read(buffer)
+		if n > 0 {
+			data = append(data, buffer[:n]...)
+			chunkTimes = append(chunkTimes, time.Since(startTime))
+		}
+
+		if errors.Is(err, io.EOF) {
+			return data, chunkTimes, nil
+		}
+
+		if err != nil {
+			return data, chunkTimes, err
+		}
+	}
+}
+
+// checkChunkTiming function checks that the first chunk of the response body was read within the MaxFirstByteTime
+// of the test case, and every next chunk within its MaxChunkInterval.
+func checkChunkTiming(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if httpReq.MaxFirstByteTime == 0 && httpReq.MaxChunkInterval == 0 {
+		return nil
+	}
+
+	if len(resp.ChunkTimes) == 0 {
+		return errors.New("chunk timing: response body is empty")
+	}
+
+	if httpReq.MaxFirstByteTime > 0 && resp.ChunkTimes[0] > httpReq.MaxFirstByteTime {
+		return fmt.Errorf("chunk timing: first byte after %s, expected within %s (chunk times %v)",
+			resp.ChunkTimes[0], httpReq.MaxFirstByteTime, resp.ChunkTimes)
+	}
+
+	if httpReq.MaxChunkInterval == 0 {
+		return nil
+	}
+
+	for i := 1; i < len(resp.ChunkTimes); i++ {
+		if interval := resp.ChunkTimes[i] - resp.ChunkTimes[i-1]; interval > httpReq.MaxChunkInterval {
+			return fmt.Errorf("chunk timing: chunk %d after %s, expected within %s (chunk times %v)", i+1,
+				interval, httpReq.MaxChunkInterval, resp.ChunkTimes)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,295 @@
+package gotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// SuiteDecoders is the decoders of the suite files of LoadSuite other than JSON, by file extension, like the YAML
+// decoder of the gotestyaml module for ".yaml" and ".yml". The suite is decoded into a struct whose fields have both
+// json and yaml tags.
+//
+// Example usage:
+//
+// ```
+// gotestyaml.Register()
+// ```
+var SuiteDecoders = map[string]func(data []byte, value interface{}) error{}
+
+// suiteFile is the content of a suite file.
+type suiteFile struct {
+	Requests []suiteRequest `json:"requests" yaml:"requests"`
+}
+
+// suiteRequest is a request of a suite file.
+type suiteRequest struct {
+	Name               string                 `json:"name" yaml:"name"`
+	Details            string                 `json:"details" yaml:"details"`
+	Method             string                 `json:"method" yaml:"method"`
+	Url                string                 `json:"url" yaml:"url"`
+	Query              map[string]string      `json:"query" yaml:"query"`
+	Headers            map[string]string      `json:"headers" yaml:"headers"`
+	ContentType        string                 `json:"content_type" yaml:"content_type"`
+	BearerToken        string                 `json:"bearer_token" yaml:"bearer_token"`
+	Body               interface{}            `json:"body" yaml:"body"`
+	ExpectedStatus     int                    `json:"expected_status" yaml:"expected_status"`
+	ExpectedBody       interface{}            `json:"expected_body" yaml:"expected_body"`
+	ExpectedHeaders    map[string]string      `json:"expected_headers" yaml:"expected_headers"`
+	ExpectedJsonFields map[string]interface{} `json:"expected_json_fields" yaml:"expected_json_fields"`
+	CaptureJson        map[string]string      `json:"capture_json" yaml:"capture_json"`
+	Tags               []string               `json:"tags" yaml:"tags"`
+}
+
+// LoadSuite function loads the requests of a suite file, runnable with Run. A JSON file is decoded with the line of
+// its errors, the other formats, like YAML, with the SuiteDecoders of their extension. The file is an object whose
+// "requests" is the list of requests, with a "method", a "url" and the optional "name", "details", "query",
+// "headers", "content_type", "bearer_token", "body", "expected_status", "expected_body", "expected_headers",
+// "expected_json_fields" and "capture_json".
+//
+// Example usage:
+//
+// ```
+// requests, err := LoadSuite("testdata/users.json")
+// if err != nil {
+// log.Fatal(err)
+// }
+// T.Run(requests)
+// ```
+func LoadSuite(path string) ([]ApiTestRequest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var suite suiteFile
+	var lines []int
+
+	extension := strings.ToLower(filepath.Ext(path))
+	if extension == ".json" {
+		if suite, lines, err = decodeJsonSuite(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	} else {
+		decoder, isPresent := SuiteDecoders[extension]
+		if !isPresent {
+			return nil, fmt.Errorf("%s: no decoder in SuiteDecoders for %q files", path, extension)
+		}
+
+		if err := decoder(data, &suite); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	requests := make([]ApiTestRequest, 0, len(suite.Requests))
+	for i, request := range suite.Requests {
+		httpReq, err := request.apiTestRequest()
+		if err != nil {
+			location := fmt.Sprintf("requests[%d]", i)
+			if i < len(lines) {
+				location += fmt.Sprintf(" (line %d)", lines[i])
+			}
+
+			return nil, fmt.Errorf("%s: %s: %w", path, location, err)
+		}
+
+		requests = append(requests, httpReq)
+	}
+
+	return requests, nil
+}
+
+// decodeJsonSuite function decodes a JSON suite file, and returns the lines of its requests. Its errors have the line
+// and column they occurred at.
+func decodeJsonSuite(data []byte) (suiteFile, []int, error) {
+	var suite suiteFile
+	var lines []int
+
+	lineAt := func(offset int64) int {
+		line, _ := jsonErrorLocation(data, offset)
+		return line
+	}
+
+	failAt := func(offset int64, err error) (suiteFile, []int, error) {
+		line, column := jsonErrorLocation(data, offset)
+		return suite, nil, fmt.Errorf("line %d, column %d: %w", line, column, err)
+	}
+
+	fail := func(decoder *json.Decoder, err error) (suiteFile, []int, error) {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+
+		offset := decoder.InputOffset()
+		if errors.As(err, &syntaxErr) {
+			offset = max(syntaxErr.Offset-1, 0)
+		} else if errors.As(err, &typeErr) {
+			offset = typeErr.Offset
+		}
+
+		return failAt(offset, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if token, err := decoder.Token(); err != nil {
+		return fail(decoder, err)
+	} else if token != json.Delim('{') {
+		return fail(decoder, errors.New("suite must be an object"))
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return fail(decoder, err)
+		}
+
+		if token != "requests" {
+			return fail(decoder, fmt.Errorf("unknown field %q", token))
+		}
+
+		if token, err := decoder.Token(); err != nil {
+			return fail(decoder, err)
+		} else if token != json.Delim('[') {
+			return fail(decoder, errors.New(`"requests" must be an array`))
+		}
+
+		for decoder.More() {
+			start := decoder.InputOffset()
+			for start < int64(len(data)) && bytes.ContainsRune([]byte(" \t\r\n,"), rune(data[start])) {
+				start++
+			}
+
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				return fail(decoder, err)
+			}
+
+			if name := unknownJsonField(raw, suiteRequestFields); name != "" {
+				return failAt(start, fmt.Errorf("unknown field %q", name))
+			}
+
+			var request suiteRequest
+			requestDecoder := json.NewDecoder(bytes.NewReader(raw))
+			requestDecoder.UseNumber()
+			if err := requestDecoder.Decode(&request); err != nil {
+				var typeErr *json.UnmarshalTypeError
+				if errors.As(err, &typeErr) {
+					return failAt(start+typeErr.Offset, err)
+				}
+
+				return failAt(start, err)
+			}
+
+			suite.Requests = append(suite.Requests, request)
+			lines = append(lines, lineAt(start))
+		}
+
+		if _, err := decoder.Token(); err != nil {
+			return fail(decoder, err)
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return fail(decoder, err)
+	}
+
+	return suite, lines, nil
+}
+
+// suiteRequestFields is the lower case names of the fields of a request of a suite file.
+var suiteRequestFields = jsonFieldNames(reflect.TypeOf(suiteRequest{}))
+
+// jsonFieldNames function returns the lower case names of the JSON fields of a struct type, matched
+// case-insensitively like encoding/json does.
+func jsonFieldNames(structType reflect.Type) map[string]bool {
+	names := make(map[string]bool, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		name, _, _ := strings.Cut(structType.Field(i).Tag.Get("json"), ",")
+		if name == "" {
+			name = structType.Field(i).Name
+		}
+
+		names[strings.ToLower(name)] = true
+	}
+
+	return names
+}
+
+// unknownJsonField function returns the first key, in sorted order, of a JSON object that is not one of the fields, or
+// "" when they are all known or the value is not an object.
+func unknownJsonField(data []byte, fields map[string]bool) string {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return ""
+	}
+
+	for _, name := range sortedKeys(object) {
+		if !fields[strings.ToLower(name)] {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// apiTestRequest function validates a request of a suite file and converts it into an ApiTestRequest.
+func (r suiteRequest) apiTestRequest() (ApiTestRequest, error) {
+	if r.Method == "" {
+		return ApiTestRequest{}, errors.New(`missing "method"`)
+	}
+
+	if r.Url == "" {
+		return ApiTestRequest{}, errors.New(`missing "url"`)
+	}
+
+	if r.ExpectedStatus != 0 && (r.ExpectedStatus < 100 || r.ExpectedStatus > 599) {
+		return ApiTestRequest{}, fmt.Errorf(`invalid "expected_status" %d`, r.ExpectedStatus)
+	}
+
+	httpReq := ApiTestRequest{
+		Name:               r.Name,
+		Details:            r.Details,
+		ApiMethod:          strings.ToUpper(r.Method),
+		ApiUrl:             r.Url,
+		Headers:            r.Headers,
+		ReqBody:            r.Body,
+		ExpectedBody:       r.ExpectedBody,
+		ExpectedHeaders:    r.ExpectedHeaders,
+		ExpectedJsonFields: r.ExpectedJsonFields,
+		CaptureJson:        r.CaptureJson,
+		Tags:               r.Tags,
+	}
+
+	if r.Query != nil {
+		httpReq.ReqParam = r.Query
+	}
+
+	if r.ContentType != "" {
+		httpReq.ContentType = r.ContentType
+	}
+
+	if r.BearerToken != "" {
+		httpReq.BearerToken = r.BearerToken
+	}
+
+	if r.ExpectedStatus != 0 {
+		httpReq.ExpectedStatus = r.ExpectedStatus
+	}
+
+	if httpReq.Details == "" {
+		httpReq.Details = httpReq.ApiMethod + " " + httpReq.ApiUrl
+	}
+
+	if _, err := http.NewRequest(httpReq.ApiMethod, "http://localhost", nil); err != nil {
+		return ApiTestRequest{}, fmt.Errorf(`invalid "method" %q`, r.Method)
+	}
+
+	return httpReq, nil
+}
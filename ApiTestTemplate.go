@@ -0,0 +1,43 @@
+package gotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"maps"
+	"text/template"
+)
+
+// renderBodyTemplate function renders the BodyTemplate of a request against the variables overridden by its
+// TemplateData, and infers its content type. Using a missing key of the data is an error.
+func (h *ApiTest) renderBodyTemplate(httpReq ApiTestRequest) ([]byte, string, error) {
+	if httpReq.ReqBody != nil {
+		return nil, "", errors.New("BodyTemplate and ReqBody cannot be both set")
+	}
+
+	bodyTemplate, err := template.New("BodyTemplate").Option("missingkey=error").Parse(httpReq.BodyTemplate)
+	if err != nil {
+		return nil, "", err
+	}
+
+	h.variableMutex.Lock()
+	data := maps.Clone(h.Variables)
+	h.variableMutex.Unlock()
+
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+
+	maps.Copy(data, httpReq.TemplateData)
+
+	var body bytes.Buffer
+	if err := bodyTemplate.Execute(&body, data); err != nil {
+		return nil, "", err
+	}
+
+	if json.Valid(body.Bytes()) {
+		return body.Bytes(), ContentTypeJson, nil
+	}
+
+	return body.Bytes(), ContentTypeText, nil
+}
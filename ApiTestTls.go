@@ -0,0 +1,70 @@
+package gotest
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ApiTestTlsCapture is the TLS details of the connection of a response.
+type ApiTestTlsCapture struct {
+	Version      uint16    // Version is the TLS version, like tls.VersionTLS13.
+	VersionName  string    // VersionName is the name of the TLS version, like "TLS 1.3".
+	CipherSuite  string    // CipherSuite is the name of the cipher suite, like "TLS_AES_128_GCM_SHA256".
+	CertSubject  string    // CertSubject is the subject of the server certificate.
+	CertIssuer   string    // CertIssuer is the issuer of the server certificate.
+	CertNotAfter time.Time // CertNotAfter is the expiry time of the server certificate.
+}
+
+// captureTls function returns the TLS details of a connection state, or nil without TLS.
+func captureTls(state *tls.ConnectionState) *ApiTestTlsCapture {
+	if state == nil {
+		return nil
+	}
+
+	capture := &ApiTestTlsCapture{
+		Version:     state.Version,
+		VersionName: tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+	}
+
+	if len(state.PeerCertificates) > 0 {
+		certificate := state.PeerCertificates[0]
+		capture.CertSubject = certificate.Subject.String()
+		capture.CertIssuer = certificate.Issuer.String()
+		capture.CertNotAfter = certificate.NotAfter
+	}
+
+	return capture
+}
+
+// checkExpectedTls function checks the TLS version of the connection against the ExpectedTlsMinVersion of the test
+// case, and that its server certificate does not expire within its AssertCertNotExpiringWithin.
+func checkExpectedTls(_ *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if httpReq.ExpectedTlsMinVersion == 0 && httpReq.AssertCertNotExpiringWithin == 0 {
+		return nil
+	}
+
+	if resp.Tls == nil {
+		return errors.New("response not received over TLS")
+	}
+
+	if resp.Tls.Version < httpReq.ExpectedTlsMinVersion {
+		return fmt.Errorf("TLS version: expected at least %s, got %s", tls.VersionName(httpReq.ExpectedTlsMinVersion),
+			resp.Tls.VersionName)
+	}
+
+	if httpReq.AssertCertNotExpiringWithin > 0 {
+		if resp.Tls.CertNotAfter.IsZero() {
+			return errors.New("server certificate not present")
+		}
+
+		if remaining := time.Until(resp.Tls.CertNotAfter); remaining < httpReq.AssertCertNotExpiringWithin {
+			return fmt.Errorf("server certificate %s expires at %s, within %s", resp.Tls.CertSubject,
+				resp.Tls.CertNotAfter.Format(time.RFC3339), httpReq.AssertCertNotExpiringWithin)
+		}
+	}
+
+	return nil
+}
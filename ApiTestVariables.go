@@ -0,0 +1,270 @@
+package gotest
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"regexp"
+)
+
+// variablePattern matches the "{{name}}" placeholders of variables.
+var variablePattern = regexp.MustCompile(`{{\s*([\w.-]+)\s*}}`)
+
+// SetVariable function sets a variable, which can then be used as "{{name}}" in the requests of the test cases.
+func (h *ApiTest) SetVariable(name string, value interface{}) {
+	h.variableMutex.Lock()
+	defer h.variableMutex.Unlock()
+
+	if h.Variables == nil {
+		h.Variables = make(map[string]interface{})
+	}
+
+	h.Variables[name] = value
+}
+
+// variable function returns the value of a variable.
+func (h *ApiTest) variable(name string) (interface{}, bool) {
+	h.variableMutex.Lock()
+	defer h.variableMutex.Unlock()
+
+	value, isPresent := h.Variables[name]
+	return value, isPresent
+}
+
+// interpolate function replaces the "{{name}}" placeholders of a text by the values of their variables. It fails
+// when a variable is undefined.
+func (h *ApiTest) interpolate(text string) (string, error) {
+	var undefined error
+
+	interpolated := variablePattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := variablePattern.FindStringSubmatch(placeholder)[1]
+
+		value, isPresent := h.variable(name)
+		if !isPresent {
+			if undefined == nil {
+				undefined = fmt.Errorf("undefined variable %q", name)
+			}
+
+			return placeholder
+		}
+
+		return fmt.Sprint(value)
+	})
+
+	return interpolated, undefined
+}
+
+// interpolateDefined function replaces the "{{name}}" placeholders of a text by the values of their variables, and
+// keeps the placeholders of the undefined variables as they are, like the literal "{{name}}" of a template body.
+func (h *ApiTest) interpolateDefined(text string) string {
+	return variablePattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		value, isPresent := h.variable(variablePattern.FindStringSubmatch(placeholder)[1])
+		if !isPresent {
+			return placeholder
+		}
+
+		return fmt.Sprint(value)
+	})
+}
+
+// computeExpectedBody function returns a copy of the request of a test case with its ExpectedBody computed by its
+// ExpectedBodyFunc, if any, from a copy of the variables.
+func (h *ApiTest) computeExpectedBody(httpReq ApiTestRequest) (ApiTestRequest, error) {
+	if httpReq.ExpectedBodyFunc == nil {
+		return httpReq, nil
+	}
+
+	if httpReq.ExpectedBody != nil {
+		return httpReq, errors.New("ExpectedBodyFunc and ExpectedBody cannot be both set")
+	}
+
+	h.variableMutex.Lock()
+	vars := maps.Clone(h.Variables)
+	h.variableMutex.Unlock()
+
+	if vars == nil {
+		vars = make(map[string]interface{})
+	}
+
+	httpReq.ExpectedBody = httpReq.ExpectedBodyFunc(vars)
+	return httpReq, nil
+}
+
+// interpolateRequest function returns a copy of the request of a test case with the variables of its URL, path
+// parameters, headers, body and form fields replaced.
+func (h *ApiTest) interpolateRequest(httpReq ApiTestRequest) (ApiTestRequest, error) {
+	var err error
+
+	texts := []*string{&httpReq.ApiUrl, &httpReq.IfNoneMatch, &httpReq.IfModifiedSince}
+	for _, text := range texts {
+		if *text, err = h.interpolate(*text); err != nil {
+			return httpReq, err
+		}
+	}
+
+	if httpReq.ReqParam, err = h.interpolateReqParam(httpReq.ReqParam); err != nil {
+		return httpReq, err
+	}
+
+	if httpReq.BearerToken != nil {
+		if httpReq.BearerToken, err = h.interpolate(httpReq.BearerToken.(string)); err != nil {
+			return httpReq, err
+		}
+	}
+
+	if httpReq.ReqBody, err = h.interpolateBody(httpReq.ReqBody); err != nil {
+		return httpReq, err
+	}
+
+	for _, fields := range []*map[string]string{&httpReq.Headers, &httpReq.RawHeaders, &httpReq.FormFields} {
+		*fields = maps.Clone(*fields)
+		for name, value := range *fields {
+			if (*fields)[name], err = h.interpolate(value); err != nil {
+				return httpReq, err
+			}
+		}
+	}
+
+	return httpReq, nil
+}
+
+// interpolateReqParam function returns a copy of the path parameters of a request with the variables of its values
+// replaced.
+func (h *ApiTest) interpolateReqParam(reqParam interface{}) (interface{}, error) {
+	switch value := reqParam.(type) {
+	case string:
+		return h.interpolate(value)
+	case map[string]string:
+		return h.interpolateBody(value)
+	case url.Values:
+		return h.interpolateValues(value)
+	case map[string][]string:
+		return h.interpolateValues(value)
+	default:
+		return reqParam, nil
+	}
+}
+
+// interpolateValues function returns a copy of parameters with several values with the variables of their values
+// replaced.
+func (h *ApiTest) interpolateValues(values map[string][]string) (map[string][]string, error) {
+	interpolated := make(map[string][]string, len(values))
+	for key, items := range values {
+		for _, item := range items {
+			text, err := h.interpolate(item)
+			if err != nil {
+				return nil, err
+			}
+
+			interpolated[key] = append(interpolated[key], text)
+		}
+	}
+
+	return interpolated, nil
+}
+
+// interpolateBody function returns a copy of a request body with the variables of its strings replaced: a raw string
+// or []byte body, and the string values of map[string]interface{}, map[string]string and []interface{} bodies. The
+// placeholders of undefined variables are kept in a raw body, which may hold a literal "{{name}}", and fail otherwise.
+func (h *ApiTest) interpolateBody(body interface{}) (interface{}, error) {
+	switch value := body.(type) {
+	case string:
+		return h.interpolateDefined(value), nil
+	case []byte:
+		return []byte(h.interpolateDefined(string(value))), nil
+	case map[string]string:
+		interpolated := make(map[string]string, len(value))
+		for key, item := range value {
+			text, err := h.interpolate(item)
+			if err != nil {
+				return nil, err
+			}
+
+			interpolated[key] = text
+		}
+
+		return interpolated, nil
+	default:
+		return h.interpolateJsonValue(body)
+	}
+}
+
+// interpolateJsonValue function returns a copy of a JSON value with the variables of its strings replaced. A string
+// made of a single placeholder is replaced by the value of its variable, keeping its JSON type.
+func (h *ApiTest) interpolateJsonValue(value interface{}) (interface{}, error) {
+	switch node := value.(type) {
+	case string:
+		if match := variablePattern.FindStringSubmatch(node); match != nil && match[0] == node {
+			variable, isPresent := h.variable(match[1])
+			if !isPresent {
+				return nil, fmt.Errorf("undefined variable %q", match[1])
+			}
+
+			return variable, nil
+		}
+
+		return h.interpolate(node)
+	case map[string]interface{}:
+		interpolated := make(map[string]interface{}, len(node))
+		for key, item := range node {
+			child, err := h.interpolateJsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+
+			interpolated[key] = child
+		}
+
+		return interpolated, nil
+	case []interface{}:
+		interpolated := make([]interface{}, len(node))
+		for i, item := range node {
+			child, err := h.interpolateJsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+
+			interpolated[i] = child
+		}
+
+		return interpolated, nil
+	default:
+		return value, nil
+	}
+}
+
+// captureVariables function sets the variables captured from the response of a test case.
+func (h *ApiTest) captureVariables(httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	for _, name := range sortedKeys(httpReq.CaptureHeaders) {
+		header := httpReq.CaptureHeaders[name]
+
+		values, isPresent := resp.Header[http.CanonicalHeaderKey(header)]
+		if !isPresent {
+			return fmt.Errorf("capture %q: header %s not present", name, header)
+		}
+
+		h.SetVariable(name, values[0])
+	}
+
+	if len(httpReq.CaptureJson) == 0 {
+		return nil
+	}
+
+	body, err := decodeJson(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range sortedKeys(httpReq.CaptureJson) {
+		value, err := lookupJsonPath(body, httpReq.CaptureJson[name])
+		if err != nil {
+			return fmt.Errorf("capture %q: %w", name, err)
+		}
+
+		h.SetVariable(name, value)
+	}
+
+	return nil
+}
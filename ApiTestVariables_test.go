@@ -0,0 +1,77 @@
+package gotest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestInterpolateRequestBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      interface{}
+		wantBody  string
+		wantError string
+	}{
+		{name: "defined variable in raw string", body: `{"id":"{{id}}"}`, wantBody: `{"id":"42"}`},
+		{name: "defined variable in []byte", body: []byte(`{"id":"{{id}}"}`), wantBody: `{"id":"42"}`},
+		{
+			name:     "literal placeholder in raw string",
+			body:     `{"template":"Hello {{user}}","id":"{{id}}"}`,
+			wantBody: `{"template":"Hello {{user}}","id":"42"}`,
+		},
+		{name: "defined variable in map", body: map[string]interface{}{"id": "{{id}}"}, wantBody: `{"id":42}`},
+		{
+			name:      "undefined variable in map",
+			body:      map[string]interface{}{"id": "{{user}}"},
+			wantError: `undefined variable "user"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var received string
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				received = string(body)
+			})
+
+			h := InitApiTestWithHandler(mux)
+			defer h.Close()
+
+			h.Output = io.Discard
+			h.SetVariable("id", 42)
+			h.CreateTest(ApiTestRequest{Details: "Create user", ApiUrl: "/users", ApiMethod: http.MethodPost,
+				ReqBody: test.body, ContentType: "application/json", ExpectedStatus: http.StatusOK})
+
+			result := h.Result[1]
+			if test.wantError != "" {
+				if result.TestStatus || !strings.Contains(result.TestError.String(), test.wantError) {
+					t.Errorf("expected the error %q, got %q", test.wantError, result.TestError.String())
+				}
+
+				return
+			}
+
+			if !result.TestStatus {
+				t.Fatalf("expected the test case to pass, got %s", result.TestError)
+			}
+
+			if received != test.wantBody {
+				t.Errorf("expected the body %s, got %s", test.wantBody, received)
+			}
+		})
+	}
+}
+
+func TestSetVariableWithoutVariables(t *testing.T) {
+	h := &ApiTest{}
+	h.SetVariable("id", 42)
+
+	if value, isPresent := h.variable("id"); !isPresent || value != 42 {
+		t.Errorf("expected the variable id to be 42, got %v", value)
+	}
+}
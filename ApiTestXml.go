@@ -0,0 +1,52 @@
+package gotest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// checkWellFormedXml function checks that a body is well-formed XML, and reports the line of the first error.
+func checkWellFormedXml(body []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+
+	for {
+		_, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		var syntaxErr *xml.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return fmt.Errorf("response body is not well-formed XML at line %d: %s", syntaxErr.Line, syntaxErr.Msg)
+		}
+
+		if err != nil {
+			return errors.New("response body is not well-formed XML: " + err.Error())
+		}
+	}
+}
+
+// checkExpectedXsd function checks that the response body is well-formed XML valid against the ExpectedXsd schema
+// of the test case, with the XsdValidator of the ApiTest.
+func checkExpectedXsd(h *ApiTest, httpReq ApiTestRequest, resp *ApiTestResponseCapture) error {
+	if httpReq.ExpectedXsd == "" {
+		return nil
+	}
+
+	if err := checkWellFormedXml(resp.Body); err != nil {
+		return err
+	}
+
+	if h.XsdValidator == nil {
+		return fmt.Errorf("cannot validate against %s: no XsdValidator", httpReq.ExpectedXsd)
+	}
+
+	if err := h.XsdValidator(httpReq.ExpectedXsd, resp.Body); err != nil {
+		return fmt.Errorf("response body is not valid against %s: %w", httpReq.ExpectedXsd, err)
+	}
+
+	return nil
+}
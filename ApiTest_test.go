@@ -0,0 +1,73 @@
+package gotest
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestCheckStatusPriority(t *testing.T) {
+	tests := []struct {
+		name      string
+		request   ApiTestRequest
+		status    int
+		wantError string
+	}{
+		{name: "expected status", request: ApiTestRequest{ExpectedStatus: http.StatusOK}, status: http.StatusOK},
+		{
+			name:      "unexpected status",
+			request:   ApiTestRequest{ExpectedStatus: http.StatusOK},
+			status:    http.StatusCreated,
+			wantError: "unexpected status code 201 Created",
+		},
+		{name: "no expected status", request: ApiTestRequest{}, status: http.StatusTeapot},
+		{
+			name: "StatusMatcher over ExpectedStatusRange and ExpectedStatus",
+			request: ApiTestRequest{StatusMatcher: StatusClass(2), ExpectedStatusRange: [2]int{400, 499},
+				ExpectedStatus: http.StatusOK},
+			status: http.StatusCreated,
+		},
+		{
+			name:      "StatusMatcher not satisfied",
+			request:   ApiTestRequest{StatusMatcher: StatusClass(2), ExpectedStatusRange: [2]int{400, 499}},
+			status:    http.StatusNotFound,
+			wantError: "status code 404 Not Found does not satisfy StatusMatcher",
+		},
+		{
+			name:    "ExpectedStatusRange over ExpectedStatus",
+			request: ApiTestRequest{ExpectedStatusRange: [2]int{200, 299}, ExpectedStatus: http.StatusOK},
+			status:  http.StatusAccepted,
+		},
+		{
+			name:      "ExpectedStatusRange not satisfied",
+			request:   ApiTestRequest{ExpectedStatusRange: [2]int{200, 299}, ExpectedStatus: http.StatusNotFound},
+			status:    http.StatusNotFound,
+			wantError: "status code 404 Not Found not in range 200-299",
+		},
+		{
+			name:    "ExpectNotModified over ExpectedStatus",
+			request: ApiTestRequest{ExpectNotModified: true, ExpectedStatus: http.StatusOK},
+			status:  http.StatusNotModified,
+		},
+		{
+			name:      "ExpectNotModified not satisfied",
+			request:   ApiTestRequest{ExpectNotModified: true, ExpectedStatus: http.StatusOK},
+			status:    http.StatusOK,
+			wantError: "unexpected status code 200 OK",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: test.status,
+				Status: strconv.Itoa(test.status) + " " + http.StatusText(test.status)}
+
+			err := checkStatus(test.request, resp)
+			if test.wantError == "" && err != nil {
+				t.Errorf("expected no error, got %q", err)
+			} else if test.wantError != "" && (err == nil || err.Error() != test.wantError) {
+				t.Errorf("expected the error %q, got %v", test.wantError, err)
+			}
+		})
+	}
+}
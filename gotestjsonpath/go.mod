@@ -0,0 +1,10 @@
+module github.com/Tvative/Go-Test/gotestjsonpath
+
+go 1.23
+
+require github.com/Tvative/Go-Test v0.1.0
+
+require (
+	github.com/PaesslerAG/gval v1.0.0
+	github.com/PaesslerAG/jsonpath v0.1.1
+)
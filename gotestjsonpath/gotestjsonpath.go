@@ -0,0 +1,46 @@
+// Package gotestjsonpath evaluates the full JSONPath expressions of the ExpectedJsonPath of the API test cases of
+// gotest, keeping the JSONPath dependency out of the gotest package.
+package gotestjsonpath
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath"
+	gotest "github.com/Tvative/Go-Test"
+)
+
+// language is the JSONPath language, with the full expressions of gval in its filters, like comparisons.
+var language = gval.Full(jsonpath.PlaceholderExtension())
+
+// Evaluate function evaluates a JSONPath expression, with filters and wildcards, against a JSON body. The numbers
+// of the body are decoded as float64, so they can be compared in the filters.
+func Evaluate(path string, body []byte) (interface{}, error) {
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, errors.New("response body is not valid JSON: " + err.Error())
+	}
+
+	evaluable, err := language.NewEvaluable(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return evaluable(context.Background(), document)
+}
+
+// Register function registers Evaluate as the JsonPathEvaluator of an ApiTest.
+//
+// Example usage:
+//
+// ```
+// T := gotest.InitApiTest()
+// gotestjsonpath.Register(T)
+// T.CreateTest(gotest.ApiTestRequest{ApiUrl: "/store", ApiMethod: http.MethodGet,
+// ExpectedJsonPath: map[string]interface{}{"$.store.book[?(@.price<10)].title": []string{"Sayings"}}})
+// ```
+func Register(h *gotest.ApiTest) {
+	h.JsonPathEvaluator = Evaluate
+}
@@ -0,0 +1,7 @@
+module github.com/Tvative/Go-Test/gotestjsonschema
+
+go 1.23
+
+require github.com/Tvative/Go-Test v0.1.0
+
+require github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
@@ -0,0 +1,70 @@
+// Package gotestjsonschema validates the JSON bodies of the API test cases of gotest against the JSON schemas of
+// their ExpectedSchemaOneOf, keeping the JSON schema dependency out of the gotest package.
+package gotestjsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	gotest "github.com/Tvative/Go-Test"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// schemas is the compiled schemas, by path.
+var (
+	schemas     = make(map[string]*jsonschema.Schema)
+	schemaMutex sync.Mutex // schemaMutex guards schemas.
+)
+
+// compile function returns the compiled schema of a path, compiling it once.
+func compile(schemaPath string) (*jsonschema.Schema, error) {
+	schemaMutex.Lock()
+	defer schemaMutex.Unlock()
+
+	if schema, isPresent := schemas[schemaPath]; isPresent {
+		return schema, nil
+	}
+
+	schema, err := jsonschema.Compile(schemaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas[schemaPath] = schema
+	return schema, nil
+}
+
+// Validate function validates a JSON body against the JSON schema file at schemaPath, of any draft from 4 to
+// 2020-12, with its numbers decoded with full precision. The schemas are compiled once.
+func Validate(schemaPath string, body []byte) error {
+	schema, err := compile(schemaPath)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return errors.New("response body is not valid JSON: " + err.Error())
+	}
+
+	return schema.Validate(document)
+}
+
+// Register function registers Validate as the JsonSchemaValidator of an ApiTest.
+//
+// Example usage:
+//
+// ```
+// T := gotest.InitApiTest()
+// gotestjsonschema.Register(T)
+// T.CreateTest(gotest.ApiTestRequest{ApiUrl: "/payments/1", ApiMethod: http.MethodGet,
+// ExpectedSchemaOneOf: []string{"testdata/card_payment.json", "testdata/bank_payment.json"}})
+// ```
+func Register(h *gotest.ApiTest) {
+	h.JsonSchemaValidator = Validate
+}
@@ -0,0 +1,7 @@
+module github.com/Tvative/Go-Test/gotestproto
+
+go 1.23
+
+require github.com/Tvative/Go-Test v0.1.0
+
+require google.golang.org/protobuf v1.36.12
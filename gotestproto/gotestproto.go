@@ -0,0 +1,55 @@
+// Package gotestproto compares the protobuf bodies of the responses of the API test cases of gotest, keeping the
+// protobuf dependency out of the gotest package.
+package gotestproto
+
+import (
+	"errors"
+	"fmt"
+
+	gotest "github.com/Tvative/Go-Test"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+// ContentTypes is the content types of protobuf bodies.
+var ContentTypes = []string{"application/x-protobuf", "application/protobuf"}
+
+// Compare function decodes a response body into a new message of the type of the expected body, a proto.Message, and
+// compares them with proto.Equal.
+func Compare(expected interface{}, actual []byte) error {
+	expectedMessage, isMessage := expected.(proto.Message)
+	if !isMessage {
+		return fmt.Errorf("expected body of type %T is not a proto.Message", expected)
+	}
+
+	actualMessage := expectedMessage.ProtoReflect().New().Interface()
+	if err := proto.Unmarshal(actual, actualMessage); err != nil {
+		return errors.New("response body is not a valid protobuf message: " + err.Error())
+	}
+
+	if !proto.Equal(expectedMessage, actualMessage) {
+		return fmt.Errorf("body mismatch\nexpected: %s\nactual: %s", prototext.Format(expectedMessage),
+			prototext.Format(actualMessage))
+	}
+
+	return nil
+}
+
+// Register function registers Compare as the comparer of the protobuf bodies of an ApiTest.
+//
+// Example usage:
+//
+// ```
+// T := gotest.InitApiTest()
+// gotestproto.Register(T)
+// T.CreateTest(gotest.ApiTestRequest{ApiUrl: "/users/1", ApiMethod: http.MethodGet, ExpectedBody: &pb.User{Id: 1}})
+// ```
+func Register(h *gotest.ApiTest) {
+	if h.BodyComparers == nil {
+		h.BodyComparers = make(map[string]gotest.ApiTestBodyComparer)
+	}
+
+	for _, contentType := range ContentTypes {
+		h.BodyComparers[contentType] = Compare
+	}
+}
@@ -0,0 +1,7 @@
+module github.com/Tvative/Go-Test/gotestyaml
+
+go 1.23
+
+require github.com/Tvative/Go-Test v0.1.0
+
+require gopkg.in/yaml.v3 v3.0.1
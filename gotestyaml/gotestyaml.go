@@ -0,0 +1,119 @@
+// Package gotestyaml decodes the YAML suite files of LoadSuite of gotest, keeping the YAML dependency out of the
+// gotest package.
+package gotestyaml
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	gotest "github.com/Tvative/Go-Test"
+	"gopkg.in/yaml.v3"
+)
+
+// Unmarshal function decodes a YAML document into value, a pointer, like yaml.Unmarshal, but fails on the keys of a
+// mapping that are not fields of its struct. Its errors have the line and column they occurred at.
+func Unmarshal(data []byte, value interface{}) error {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return err
+	}
+
+	if len(document.Content) == 0 {
+		return nil
+	}
+
+	return decode(document.Content[0], reflect.ValueOf(value).Elem())
+}
+
+// decode function decodes a node into a value, field by field for the structs and element by element for the slices
+// of structs, so that the unknown keys and the invalid values are located.
+func decode(node *yaml.Node, value reflect.Value) error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	if node.ShortTag() == "!!null" {
+		return nil
+	}
+
+	switch {
+	case value.Kind() == reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return errorAt(node, fmt.Errorf("expected a mapping, got %s", node.ShortTag()))
+		}
+
+		fields := fieldIndexes(value.Type())
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, child := node.Content[i], node.Content[i+1]
+
+			index, isKnown := fields[key.Value]
+			if !isKnown {
+				return errorAt(key, fmt.Errorf("unknown field %q", key.Value))
+			}
+
+			if err := decode(child, value.Field(index)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Struct:
+		if node.Kind != yaml.SequenceNode {
+			return errorAt(node, fmt.Errorf("expected a sequence, got %s", node.ShortTag()))
+		}
+
+		elements := reflect.MakeSlice(value.Type(), len(node.Content), len(node.Content))
+		for i, child := range node.Content {
+			if err := decode(child, elements.Index(i)); err != nil {
+				return err
+			}
+		}
+
+		value.Set(elements)
+		return nil
+	}
+
+	if err := node.Decode(value.Addr().Interface()); err != nil {
+		return errorAt(node, fmt.Errorf("cannot decode %s into %s", node.ShortTag(), value.Type()))
+	}
+
+	return nil
+}
+
+// fieldIndexes function returns the indexes of the fields of a struct type, by their YAML names.
+func fieldIndexes(structType reflect.Type) map[string]int {
+	indexes := make(map[string]int, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		name, _, _ := strings.Cut(structType.Field(i).Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(structType.Field(i).Name)
+		}
+
+		indexes[name] = i
+	}
+
+	return indexes
+}
+
+// errorAt function returns an error with the line and column of a node.
+func errorAt(node *yaml.Node, err error) error {
+	return fmt.Errorf("line %d, column %d: %w", node.Line, node.Column, err)
+}
+
+// Register function registers Unmarshal as the SuiteDecoders of the ".yaml" and ".yml" files of LoadSuite.
+//
+// Example usage:
+//
+// ```
+// gotestyaml.Register()
+// requests, err := gotest.LoadSuite("testdata/users.yaml")
+// ```
+func Register() {
+	gotest.SuiteDecoders[".yaml"] = Unmarshal
+	gotest.SuiteDecoders[".yml"] = Unmarshal
+}